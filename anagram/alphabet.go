@@ -0,0 +1,162 @@
+package anagram
+
+import (
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxExtraLetters is how many letters beyond a-z an alphabet can keep
+// distinct. It's what's left of the RuneCluster after the 26 basic letters.
+const maxExtraLetters = maxLetters - 26
+
+// Alphabet decides which letters count as distinct when matching anagrams.
+// The basic letters a-z always have their own slot. Letters in Extra get
+// the remaining slots, in order, so a language can keep e.g. 'ñ' apart from
+// 'n'. Any other letter is folded to a-z: first through Folds, then through
+// the built-in Latin folding table, so 'é' becomes "e" and 'ß' becomes "ss".
+// Letters that fold to nothing can't be used in anagrams.
+type Alphabet struct {
+	Name  string
+	Extra []rune
+	Folds map[rune]string
+}
+
+// LatinAlphabet folds every accented letter to its plain a-z form. It's the
+// alphabet of any Dictionary that doesn't have one of its own.
+var LatinAlphabet = &Alphabet{Name: "Latin"}
+
+func NewAlphabet(name string, extra []rune, folds map[rune]string) (*Alphabet, error) {
+	if len(extra) > maxExtraLetters {
+		return nil, errors.New("Too many extra letters in alphabet " + name)
+	}
+
+	a := &Alphabet{Name: name, Extra: make([]rune, len(extra)), Folds: folds}
+	for i, r := range extra {
+		a.Extra[i] = unicode.ToLower(r)
+	}
+
+	return a, nil
+}
+
+// Index returns the RuneCluster slot for a lower case letter, or -1 if the
+// letter has no slot of its own and needs folding.
+func (a *Alphabet) Index(r rune) int {
+	if r >= 'a' && r <= 'z' {
+		return int(r - 'a')
+	}
+	for i, x := range a.Extra {
+		if x == r {
+			return 26 + i
+		}
+	}
+	return -1
+}
+
+// Fold returns the letters r stands for in this alphabet, or "" if it can't
+// be represented.
+func (a *Alphabet) Fold(r rune) string {
+	r = unicode.ToLower(r)
+	if a.Index(r) >= 0 {
+		return string(r)
+	}
+	if f, ok := a.Folds[r]; ok {
+		return f
+	}
+	return latinFolds[r]
+}
+
+// FoldString lower cases s and folds each letter. Letters that can't be
+// represented are kept as they are so they still show up, they just never
+// match anything.
+func (a *Alphabet) FoldString(s string) string {
+	b := strings.Builder{}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			b.WriteRune(r)
+		} else if f := a.Fold(r); f != "" {
+			b.WriteString(f)
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// letterString is the letters of word, folded the way RuneClusters fold
+// them, without spaces or punctuation.
+func (a *Alphabet) letterString(word string) string {
+	b := strings.Builder{}
+	for _, r := range a.FoldString(word) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Covers reports whether every letter in word can be represented.
+func (a *Alphabet) Covers(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) && a.Fold(r) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprint is a hash of the letters the alphabet keeps distinct and its
+// folds, so two alphabets with the same fingerprint count letters the same
+// way whatever they're called.
+func (a *Alphabet) fingerprint() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(string(a.Extra)))
+	folds := make([]rune, 0, len(a.Folds))
+	for r := range a.Folds {
+		folds = append(folds, r)
+	}
+	slices.Sort(folds)
+	for _, r := range folds {
+		h.Write([]byte("\x00" + string(r) + "=" + a.Folds[r]))
+	}
+	return h.Sum64()
+}
+
+// latinFolds maps the accented and ligature letters of the Latin-1 and
+// Latin Extended-A blocks onto a-z.
+var latinFolds = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ĳ': "ij", 'þ': "th",
+	'ð': "d", 'đ': "d", 'ħ': "h", 'ı': "i", 'ĸ': "k", 'ŀ': "l", 'ł': "l",
+	'ŉ': "n", 'ŋ': "n", 'ø': "o", 'ŧ': "t", 'ſ': "s",
+}
+
+func init() {
+	accented := map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ď",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥ",
+		'i': "ìíîïĩīĭį",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľ",
+		'n': "ñńņň",
+		'o': "òóôõöōŏő",
+		'r': "ŕŗř",
+		's': "śŝşš",
+		't': "ţť",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	}
+	for base, variants := range accented {
+		for _, r := range variants {
+			latinFolds[unicode.ToLower(r)] = string(base)
+		}
+	}
+}
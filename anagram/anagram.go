@@ -0,0 +1,791 @@
+package anagram
+
+import (
+	"context"
+	// "fmt"
+	"log"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type dictPair struct {
+	Word    string
+	cluster *RuneCluster
+	letters int       // number of letters in cluster
+	info    *WordInfo // frequency and tags, nil if the dictionary has none
+}
+
+type annotatedDict []dictPair
+
+// NewAnnotatedDict pairs each word with its RuneCluster under the
+// dictionary's alphabet. Words with letters the alphabet can't represent are
+// left out.
+func NewAnnotatedDict(d *Dictionary) annotatedDict {
+	return annotateWith(d, d.alphabet())
+}
+
+// annotateWith pairs each word of d with its RuneCluster under alphabet.
+func annotateWith(d *Dictionary, alphabet *Alphabet) annotatedDict {
+	d.ensureLoaded()
+	var ad annotatedDict = make(annotatedDict, 0, len(d.Words))
+
+	for _, word := range d.Words {
+		if alphabet.Covers(word) {
+			rc := alphabet.Cluster(word)
+			var info *WordInfo
+			if wi, ok := d.Info[word]; ok {
+				info = &wi
+			}
+			ad = append(ad, dictPair{word, rc, rc.Size(), info})
+		}
+	}
+
+	return ad
+}
+
+// GetAnnotatedDict returns a cached annotated dict, building it on first call
+// and again whenever the words or the alphabet have changed.
+func GetAnnotatedDict(d *Dictionary) annotatedDict {
+	d.ensureLoaded()
+	d.annotationLock.Lock()
+	defer d.annotationLock.Unlock()
+	if !d.annotationsCurrent() {
+		d.setAnnotated(NewAnnotatedDict(d))
+	}
+	return d.annotated
+}
+
+// setAnnotated caches ad as the annotations of d's words for its alphabet.
+// The caller holds annotationLock, or is the only one who can see d.
+func (d *Dictionary) setAnnotated(ad annotatedDict) {
+	d.annotated = ad
+	d.annotatedAlphabet = d.alphabet()
+	d.annotatedWords = d.Words
+}
+
+// annotationsCurrent reports whether d's cached annotations were made from
+// its current words with its current alphabet. A change to the words is noticed
+// when Words is replaced, as the private dictionary's is when it's edited.
+// The caller holds annotationLock.
+func (d *Dictionary) annotationsCurrent() bool {
+	return d.annotated != nil && d.annotatedAlphabet == d.alphabet() && sameSlice(d.annotatedWords, d.Words)
+}
+
+// sameSlice reports whether a and b are the same slice, not just equal ones.
+func sameSlice[T any](a, b []T) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+func FilterAnnotatedDict(input string, d *Dictionary) (annotatedDict, *RuneCluster) {
+	if d == nil {
+		log.Panicln("Got null dictionary for input ", input)
+	}
+
+	ad := GetAnnotatedDict(d)
+
+	rc := d.alphabet().Cluster(input)
+
+	filtered := ad.Filter(rc)
+
+	sort.Sort(filtered) // for efficientcy we need ot sort decending by size
+
+	return filtered, rc
+}
+
+func (ad annotatedDict) Filter(target *RuneCluster) annotatedDict {
+	retVal := make(annotatedDict, 0, len(ad)/2) // half is probably overly generous
+
+	for _, dp := range ad {
+		if dp.cluster.SubSetOf(target) {
+			retVal = append(retVal, dp)
+		}
+	}
+
+	return retVal
+}
+
+// FilterWords returns the entries whose letter count and frequency c
+// allows.
+func (ad annotatedDict) FilterWords(c Constraints) annotatedDict {
+	if c.MinWordLength <= 0 && c.MaxWordLength <= 0 && c.MinFrequency <= 0 {
+		return ad
+	}
+
+	retVal := make(annotatedDict, 0, len(ad))
+	for _, dp := range ad {
+		if c.allowsLength(dp.letters) && c.allowsFrequency(dp.info) {
+			retVal = append(retVal, dp)
+		}
+	}
+
+	return retVal
+}
+
+// letterRange returns the fewest and most letters of any entry.
+func (ad annotatedDict) letterRange() (int, int) {
+	shortest, longest := ad[0].letters, ad[0].letters
+	for _, dp := range ad[1:] {
+		shortest = min(shortest, dp.letters)
+		longest = max(longest, dp.letters)
+	}
+	return shortest, longest
+}
+
+func (ad annotatedDict) Swap(i, j int) {
+	ad[i], ad[j] = ad[j], ad[i]
+}
+
+func (ad annotatedDict) Len() int {
+	return len(ad)
+}
+
+func (ad annotatedDict) Less(i, j int) bool {
+	// sort first by length (decending) then by alphabet (decending)
+	return lessWord(ad[i].Word, ad[j].Word)
+}
+
+// Constraints limit the shape of the anagrams a search produces. Zero in
+// any field means no limit. Words from included phrases count towards the
+// number of words but aren't subject to the length limits.
+//
+// MinFrequency leaves out words less common than it. Words without
+// frequency data are always kept, so it has no effect on plain word lists.
+//
+// Leftover and MaxLeftover make partial anagrams. The letters of Leftover
+// are never used, and up to MaxLeftover more letters may be left unused.
+//
+// Pattern describes the words wanted, see ParsePattern. Only anagrams are
+// matched against it.
+type Constraints struct {
+	MinWords      int
+	MaxWords      int
+	MinWordLength int
+	MaxWordLength int
+	MinFrequency  float64
+	Leftover      string
+	MaxLeftover   int
+	Pattern       string `json:",omitempty"`
+}
+
+// CommonFrequency is the MinFrequency for "common words only".
+const CommonFrequency = 0.5
+
+func (c Constraints) IsZero() bool {
+	return c == Constraints{}
+}
+
+func (c Constraints) allowsLength(letters int) bool {
+	return (c.MinWordLength <= 0 || letters >= c.MinWordLength) && (c.MaxWordLength <= 0 || letters <= c.MaxWordLength)
+}
+
+func (c Constraints) allowsFrequency(info *WordInfo) bool {
+	return c.MinFrequency <= 0 || info == nil || info.Frequency >= c.MinFrequency
+}
+
+func (c Constraints) allowsCount(words int) bool {
+	return (c.MinWords <= 0 || words >= c.MinWords) && (c.MaxWords <= 0 || words <= c.MaxWords)
+}
+
+// SearchOptions controls how the search is spread across goroutines and
+// what it's allowed to produce.
+type SearchOptions struct {
+	// Workers is the number of goroutines exploring top-level branches.
+	// Zero means runtime.GOMAXPROCS(0); one searches on a single goroutine.
+	Workers int
+	// Unordered lets workers emit results as soon as they find them instead
+	// of in dictionary order. It's faster, but the order varies between runs.
+	Unordered bool
+	// Constraints are applied while searching, so branches that can't meet
+	// them are never explored.
+	Constraints Constraints
+	// MemoSize is how many dead ends each worker remembers, so a branch that
+	// reaches the same letters as one that found nothing is dropped at once.
+	// Each takes about 100 bytes. Zero means default_memo_size; a negative
+	// value turns remembering off.
+	MemoSize int
+	// Progress, if not nil, is kept up to date with how much of the search
+	// is done.
+	Progress *Progress
+}
+
+const (
+	default_memo_size = 1 << 15
+	// Branches with fewer words than this are quicker to search again than
+	// to look up.
+	min_memo_dict = 32
+)
+
+// DefaultSearchOptions uses every core while keeping results in the same
+// order a single-threaded search would produce.
+var DefaultSearchOptions = SearchOptions{}
+
+func (opts SearchOptions) workers() int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func FindAnagrams(input string, include []string, dictionary *Dictionary) <-chan string {
+	return FindAnagramsContext(context.Background(), input, include, dictionary)
+}
+
+// FindAnagramsContext is like FindAnagrams, but the search stops and the
+// channel is closed as soon as ctx is cancelled, so callers that abandon the
+// channel don't leave the generator running.
+func FindAnagramsContext(ctx context.Context, input string, include []string, dictionary *Dictionary) <-chan string {
+	return FindAnagramsWithOptions(ctx, input, include, dictionary, DefaultSearchOptions)
+}
+
+func FindAnagramsWithOptions(ctx context.Context, input string, include []string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	outputChan := make(chan string, 10)
+
+	s := newSearcher(ctx, dictionary, opts)
+	go s.makeAnagrams(input, include, dictionary, outputChan)
+
+	return outputChan
+}
+
+// PartialAnagram is a result along with the letters of the input it didn't
+// use.
+type PartialAnagram struct {
+	Anagram  string
+	Leftover *RuneCluster
+}
+
+// FindPartialAnagrams is like FindAnagramsWithOptions, but pairs each result
+// with its leftover letters. See Constraints.Leftover and MaxLeftover.
+func FindPartialAnagrams(ctx context.Context, input string, include []string, dictionary *Dictionary, opts SearchOptions) <-chan PartialAnagram {
+	outputChan := make(chan PartialAnagram, 10)
+
+	go func() {
+		defer close(outputChan)
+		for result := range FindAnagramsWithOptions(ctx, input, include, dictionary, opts) {
+			select {
+			case outputChan <- PartialAnagram{result, dictionary.alphabet().Leftover(input, result)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return outputChan
+}
+
+// Leftover returns the letters of input that anagram doesn't use, or nil if
+// anagram uses letters input doesn't have.
+func (a *Alphabet) Leftover(input, anagram string) *RuneCluster {
+	leftover, err := a.Cluster(input).Minus(a.Cluster(anagram))
+	if err != nil {
+		return nil
+	}
+	return leftover
+}
+
+// searcher holds what stays fixed for the length of one search.
+type searcher struct {
+	ctx      context.Context
+	opts     SearchOptions
+	alphabet *Alphabet       // the searched dictionary's
+	words    annotatedDict   // every word the search can use
+	exact    *signatureIndex // words by their letters, built when first needed
+	once     sync.Once
+	pattern  *Pattern // the words results must match, if any
+}
+
+func newSearcher(ctx context.Context, dictionary *Dictionary, opts SearchOptions) *searcher {
+	return &searcher{ctx: ctx, opts: opts, alphabet: dictionary.alphabet()}
+}
+
+func (s *searcher) makeAnagrams(input string, include []string, dictionary *Dictionary, output chan<- string) {
+	defer func() {
+		if s.ctx.Err() == nil {
+			s.opts.Progress.finish()
+		}
+		log.Println("Closing output channel for ", input)
+		close(output)
+	}()
+
+	if strings.TrimSpace(input) == "" {
+		return
+	}
+
+	filtered, target, ok := s.prepare(input, dictionary)
+	if ok {
+		filtered, ok = s.preparePattern(filtered)
+	}
+	if !ok {
+		return
+	}
+
+	// The whole search is a share of 1 when following its progress.
+	share := 0.0
+	if s.opts.Progress != nil {
+		share = 1
+	}
+	if len(include) > 0 {
+		share /= float64(len(include))
+	}
+
+	s.eachStart(include, filtered, target, func(phrase string, words int, target *RuneCluster, dict annotatedDict) {
+		s.findTuplesParallel(phrase, words, target, dict, output, share)
+	})
+}
+
+// prepare finds the words of dictionary the search can use and the letters
+// it has to use up, and sets s.words. It reports false if the constraints
+// rule out any results.
+func (s *searcher) prepare(input string, dictionary *Dictionary) (annotatedDict, *RuneCluster, bool) {
+	filtered, target := FilterAnnotatedDict(input, dictionary)
+	filtered = filtered.FilterWords(s.opts.Constraints)
+
+	if s.opts.Constraints.Leftover != "" {
+		var err error
+		target, err = target.Minus(s.alphabet.Cluster(s.opts.Constraints.Leftover))
+		if err != nil {
+			log.Println("Leftover \"" + s.opts.Constraints.Leftover + "\" not a subset of input")
+			return nil, nil, false
+		}
+		filtered = filtered.Filter(target)
+	}
+	s.words = filtered
+
+	// fmt.Printf("For input \"%s\" filtered is %d elements\n", input, len(filtered))
+	// for _, dp := range filtered[:10] {
+	// 	fmt.Print(dp.Word, " ")
+	// }
+	// fmt.Println("")
+
+	return filtered, target, true
+}
+
+// eachStart calls search with each place the search starts from: after each
+// of the included phrases that fits in target, or from nothing if there
+// aren't any.
+func (s *searcher) eachStart(include []string, filtered annotatedDict, target *RuneCluster, search func(phrase string, words int, target *RuneCluster, dict annotatedDict)) {
+	if len(include) == 0 {
+		search("", 0, target, filtered)
+		return
+	}
+
+	includedDone := 0
+	for _, phrase := range include {
+		if s.ctx.Err() != nil {
+			return
+		}
+		trimmedPhrase := strings.TrimSpace(phrase)
+		if trimmedPhrase == "" {
+			continue
+		}
+		phraseRC := s.alphabet.Cluster(trimmedPhrase)
+		if !phraseRC.SubSetOf(target) {
+			log.Println("Phrase \"" + trimmedPhrase + "\" not a subset of input")
+			continue
+		}
+		includedDone += 1
+		newTarget, _ := target.Minus(phraseRC)
+		newFiltered := filtered.Filter(newTarget)
+
+		// fmt.Printf("For included phrase \"%s\" filtered is %d elements\n", phrase, len(newFiltered))
+		// for _, dp := range newFiltered[:10] {
+		// 	fmt.Print(dp.Word, " ")
+		// }
+		// fmt.Println("")
+
+		search(trimmedPhrase, len(strings.Fields(trimmedPhrase)), newTarget, newFiltered)
+	}
+	if includedDone == 0 {
+		log.Println("Can't make anything with these included phrases")
+	}
+}
+
+// suffixSums builds a suffix-sum array: suffixCounts[i] holds the combined
+// rune counts for dict[i:]. This lets us check feasibility at each loop
+// iteration in O(26) instead of rebuilding from scratch in O(n*26). A word
+// can be used again, so each counts as many times as it fits in target.
+func suffixSums(dict annotatedDict, target *RuneCluster) []RuneCluster {
+	suffixCounts := make([]RuneCluster, len(dict))
+	var sum RuneCluster
+	for i := len(dict) - 1; i >= 0; i-- {
+		sum.addTimes(dict[i].cluster, dict[i].cluster.timesIn(target))
+		suffixCounts[i] = sum
+	}
+	return suffixCounts
+}
+
+// countBranches is how many words of the dict suffixCounts was made from can
+// start a phrase for target. After that the rest of the words don't have
+// enough letters between them.
+func (s *searcher) countBranches(target *RuneCluster, suffixCounts []RuneCluster) int {
+	branches := 0
+	for branches < len(suffixCounts) && s.covers(target, &suffixCounts[branches]) {
+		branches += 1
+	}
+	return branches
+}
+
+// join adds word to the end of the phrase current.
+func join(current, word string) string {
+	if current == "" {
+		return word
+	}
+	return current + " " + word
+}
+
+// contains reports whether word is in the dict, which must be in
+// annotatedDict order.
+func (ad annotatedDict) contains(word string) bool {
+	i := ad.position(word)
+	return i < len(ad) && ad[i].Word == word
+}
+
+// position is where word is, or would go, in ad, which must be sorted by
+// lessWord.
+func (ad annotatedDict) position(word string) int {
+	return sort.Search(len(ad), func(i int) bool {
+		return !lessWord(ad[i].Word, word)
+	})
+}
+
+// mustBeLastWord reports whether the next word has to finish the phrase,
+// either because the letters left can't make two words from dict or
+// because the constraints allow only one more. Then only words with exactly
+// the letters of target will do, and s.exact finds them without trying the
+// rest.
+func (s *searcher) mustBeLastWord(words int, target *RuneCluster, dict annotatedDict) bool {
+	if s.opts.Constraints.MaxLeftover > 0 {
+		return false // the last word doesn't have to use every letter
+	}
+	if c := s.opts.Constraints; c.MaxWords > 0 && words+1 >= c.MaxWords {
+		return true
+	}
+	shortest, _ := dict.letterRange()
+	return target.Size() < 2*shortest
+}
+
+// extend returns the phrase and remaining target after choosing dict[index],
+// along with the words still usable for the rest of the phrase.
+func extend(current string, target *RuneCluster, dict annotatedDict, index int) (string, *RuneCluster, annotatedDict) {
+	dp := dict[index]
+	trial := join(current, dp.Word)
+
+	newTarget, err := target.Minus(dp.cluster)
+	if err != nil {
+		panic(err) // this shouldn't be possible
+	}
+
+	return trial, newTarget, dict[index:].Filter(newTarget)
+}
+
+// covers reports whether the letters of available can use up target, apart
+// from the MaxLeftover letters that are allowed to go unused.
+func (s *searcher) covers(target, available *RuneCluster) bool {
+	if s.opts.Constraints.MaxLeftover <= 0 {
+		return target.SubSetOf(available)
+	}
+	return target.shortfall(available) <= s.opts.Constraints.MaxLeftover
+}
+
+// isPartial reports whether current can be emitted with target left over.
+func (s *searcher) isPartial(target *RuneCluster) bool {
+	return s.opts.Constraints.MaxLeftover > 0 && target.Size() <= s.opts.Constraints.MaxLeftover
+}
+
+// emit sends a finished phrase of the given number of words, if the
+// constraints allow it, reporting whether they did.
+func (s *searcher) emit(current string, words int, output chan<- string) bool {
+	if current == "" || !s.opts.Constraints.allowsCount(words) {
+		return false
+	}
+	if s.pattern != nil {
+		var ok bool
+		if current, ok = s.pattern.arrange(current); !ok {
+			return false
+		}
+	}
+
+	select {
+	case output <- current:
+	case <-s.ctx.Done():
+	}
+	return true
+}
+
+// canFinish reports whether the word count constraints can still be met
+// using entries from dict, given the words used so far and the letters left.
+func (s *searcher) canFinish(words int, target *RuneCluster, dict annotatedDict) bool {
+	c := s.opts.Constraints
+	if c.MaxWords <= 0 && c.MinWords <= 0 {
+		return true
+	}
+	if c.MaxWords > 0 && words >= c.MaxWords {
+		return false
+	}
+
+	letters := target.Size()
+	shortest, longest := dict.letterRange()
+	if c.MaxWords > 0 && letters-c.MaxLeftover > (c.MaxWords-words)*longest {
+		return false // even the longest words can't use up the letters in time
+	}
+	if c.MinWords > 0 && shortest > 0 && words+letters/shortest < c.MinWords {
+		return false // even the shortest words can't make enough of them
+	}
+	return true
+}
+
+// findTuplesParallel splits the top level of the search across a pool of
+// workers, one dictionary entry per job. Unless opts.Unordered is set, each
+// job writes to its own channel and the channels are drained in dictionary
+// order, so the output matches findTuples exactly.
+func (s *searcher) findTuplesParallel(current string, words int, target *RuneCluster, dict annotatedDict, output chan<- string, share float64) {
+	workers := s.opts.workers()
+	if workers <= 1 || len(dict) < 2 || target.IsEmpty() || !s.canFinish(words, target, dict) {
+		s.findTuples(current, words, target, dict, output, s.newDeadEnds(), share)
+		return
+	}
+
+	if s.isPartial(target) {
+		s.emit(current, words, output)
+	}
+
+	suffixCounts := suffixSums(dict, target)
+	branches := s.countBranches(target, suffixCounts)
+	shares := s.splitShare(share, target, dict, branches)
+	if shares == nil {
+		defer func() {
+			if s.ctx.Err() == nil {
+				s.opts.Progress.add(share)
+			}
+		}()
+	}
+
+	type job struct {
+		index  int
+		output chan<- string
+		share  float64
+	}
+	jobs := make(chan job)
+	// pending holds each ordered job's channel, in dictionary order. Its
+	// capacity bounds how far the workers can get ahead of the reader.
+	pending := make(chan chan string, workers*2)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dead := s.newDeadEnds()
+			for j := range jobs {
+				trial, newTarget, newDict := extend(current, target, dict, j.index)
+				s.findTuples(trial, words+1, newTarget, newDict, j.output, dead, j.share)
+				if !s.opts.Unordered {
+					close(j.output)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for index := 0; index < branches; index++ {
+			var ch chan string
+			j := job{index, output, 0}
+			if shares != nil {
+				j.share = shares[index]
+			}
+			if !s.opts.Unordered {
+				ch = make(chan string, 10)
+				select {
+				case pending <- ch:
+				case <-s.ctx.Done():
+					return
+				}
+				j.output = ch
+			}
+			select {
+			case jobs <- j:
+			case <-s.ctx.Done():
+				if ch != nil {
+					close(ch)
+				}
+				return
+			}
+		}
+	}()
+
+	for ch := range pending {
+		for result := range ch {
+			select {
+			case output <- result:
+			case <-s.ctx.Done():
+			}
+		}
+	}
+
+	wg.Wait()
+}
+
+// findTuples emits every phrase that starts with current and uses up target
+// with words from dict, reporting whether it found any. Branches that find
+// nothing are remembered in dead, if it isn't nil, and skipped when another
+// path reaches them. share is the branch's part of the whole search, see
+// Progress.
+func (s *searcher) findTuples(current string, words int, target *RuneCluster, dict annotatedDict, output chan<- string, dead *deadEnds, share float64) bool {
+	if dead == nil || len(dict) < min_memo_dict || target.IsEmpty() {
+		return s.searchTuples(current, words, target, dict, output, dead, share)
+	}
+
+	key, ok := s.deadEndKey(words, target)
+	if !ok {
+		return s.searchTuples(current, words, target, dict, output, dead, share)
+	}
+	start := s.words.position(dict[0].Word)
+	if dead.has(key, start) {
+		s.opts.Progress.add(share)
+		return false
+	}
+	found := s.searchTuples(current, words, target, dict, output, dead, share)
+	if !found && s.ctx.Err() == nil {
+		dead.add(key, start)
+	}
+	return found
+}
+
+func (s *searcher) searchTuples(current string, words int, target *RuneCluster, dict annotatedDict, output chan<- string, dead *deadEnds, share float64) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	if share > 0 {
+		// Whatever isn't passed on to the branches below counts once this
+		// one is done.
+		defer func() { s.opts.Progress.add(share) }()
+	}
+
+	if s.pattern != nil && !s.pattern.allows(current) {
+		return false
+	}
+
+	if target.IsEmpty() {
+		return s.emit(current, words, output)
+	}
+
+	found := false
+	if s.isPartial(target) {
+		found = s.emit(current, words, output)
+	}
+
+	if len(dict) == 0 || !s.canFinish(words, target, dict) {
+		return found
+	}
+
+	if s.mustBeLastWord(words, target, dict) {
+		s.once.Do(func() {
+			s.exact = newSignatureIndex(s.words)
+		})
+		s.exact.lookup(target, func(dp *dictPair) {
+			if dict.contains(dp.Word) && s.emit(join(current, dp.Word), words+1, output) {
+				found = true
+			}
+		})
+		return found
+	}
+
+	suffixCounts := suffixSums(dict, target)
+
+	// Check if the full dictionary can cover the target at all
+	if !s.covers(target, &suffixCounts[0]) {
+		return found
+	}
+
+	var shares []float64
+	if s.opts.Progress != nil && share >= min_split_share {
+		shares = s.splitShare(share, target, dict, s.countBranches(target, suffixCounts))
+	}
+
+	for index := range dict {
+		// Check if dict[index:] can still cover the target
+		if !s.covers(target, &suffixCounts[index]) {
+			break // remaining words can't help, and they only get smaller
+		}
+
+		if s.ctx.Err() != nil {
+			return found
+		}
+
+		trial, newTarget, newDict := extend(current, target, dict, index)
+
+		// fmt.Printf("working on '%s', %d possibilities left\n", trial, len(newDict))
+
+		branchShare := 0.0
+		if shares != nil {
+			branchShare = shares[index]
+			share -= branchShare
+		}
+		if s.findTuples(trial, words+1, newTarget, newDict, output, dead, branchShare) {
+			found = true
+		}
+	}
+	return found
+}
+
+// deadEndKey identifies the letters left in a branch, along with the number
+// of words so far when that's limited. Letter counts over 255 can't be
+// keyed, so those branches aren't remembered.
+func (s *searcher) deadEndKey(words int, target *RuneCluster) (deadEndKey, bool) {
+	var key deadEndKey
+	for i, n := range target {
+		if n > 255 {
+			return key, false
+		}
+		key.letters[i] = uint8(n)
+	}
+	if c := s.opts.Constraints; c.MinWords > 0 || c.MaxWords > 0 {
+		key.words = words
+	}
+	return key, true
+}
+
+type deadEndKey struct {
+	letters [maxLetters]uint8
+	words   int
+}
+
+// deadEnds remembers branches that found nothing. A branch's dict is always
+// the words of s.words from some start on that fit in its letters, so if
+// the letters can't be used up from one start, they can't from any later
+// one either, and only the earliest needs keeping. Each worker has its own,
+// so it needs no locking. Once it's full, new dead ends are forgotten.
+type deadEnds struct {
+	keys map[deadEndKey]int
+	size int
+}
+
+// newDeadEnds returns an empty set sized by opts.MemoSize, or nil if
+// remembering is turned off. Whether a branch can match a pattern depends
+// on its words as well as its letters, so nothing is remembered then.
+func (s *searcher) newDeadEnds() *deadEnds {
+	size := s.opts.MemoSize
+	if size < 0 || s.pattern != nil {
+		return nil
+	}
+	if size == 0 {
+		size = default_memo_size
+	}
+	return &deadEnds{make(map[deadEndKey]int), size}
+}
+
+func (de *deadEnds) has(key deadEndKey, start int) bool {
+	earliest, ok := de.keys[key]
+	return ok && earliest <= start
+}
+
+func (de *deadEnds) add(key deadEndKey, start int) {
+	if earliest, ok := de.keys[key]; ok {
+		de.keys[key] = min(earliest, start)
+	} else if len(de.keys) < de.size {
+		de.keys[key] = start
+	}
+}
@@ -0,0 +1,706 @@
+package anagram
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// --- Helpers ---
+
+// collectAll drains the channel and returns all results as a sorted slice.
+func collectAll(ch <-chan string) []string {
+	var results []string
+	for r := range ch {
+		results = append(results, r)
+	}
+	sort.Strings(results)
+	return results
+}
+
+// collectN reads up to n results from the channel, then drains the rest.
+func collectN(ch <-chan string, n int) []string {
+	var results []string
+	for r := range ch {
+		if len(results) < n {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// smallDict is a small hand-crafted dictionary for correctness tests.
+var smallDict = &Dictionary{
+	Name:  "small",
+	Words: []string{"pneumatic", "death", "hated", "foobar"},
+}
+
+// mediumDict has enough words to exercise pruning and multi-word anagrams.
+var mediumDict = &Dictionary{
+	Name: "medium",
+	Words: []string{
+		"act", "acts", "art", "arts", "ate", "cat", "cats", "ear", "ears",
+		"east", "eat", "eats", "era", "eras", "rat", "rats", "rest", "sat",
+		"sea", "seat", "set", "star", "stare", "state", "taste", "tea", "teas",
+		"tear", "tears", "test", "the", "trace", "crate", "crest", "race",
+		"rate", "rates", "scar", "scare", "care", "cares", "acre", "acres",
+		"a", "i",
+	},
+}
+
+// --- Correctness Tests ---
+
+func TestAnagrams(t *testing.T) {
+	testDict := NewDictionary("testing")
+	testDict.Words = []string{"pneumatic", "death", "hated", "foobar"}
+
+	ad := NewAnnotatedDict(testDict)
+
+	if len(ad) != 4 {
+		t.Error("Didn't annotate the whole test dictionary")
+	}
+
+	results := FindAnagrams("Mitch Patenaude", make([]string, 0), testDict)
+
+	result1, ok1 := <-results
+	result2, ok2 := <-results
+
+	if !ok1 {
+		t.Error("Didn't produce any results")
+		return
+	} else if result1 != "pneumatic death" && result1 != "pneumatic hated" {
+		t.Error("Got unexpected result " + result1)
+	}
+
+	if !ok2 {
+		t.Error("Didn't find all possibilities")
+	} else if result2 != "pneumatic hated" && result2 != "pneumatic death" {
+		t.Error("Got unexpected second result " + result2)
+	}
+
+	if result1 == result2 {
+		t.Error("Repeated result " + result1)
+	}
+
+	result3, ok3 := <-results
+	if ok3 {
+		t.Error("Got too many results")
+	}
+	if result3 != "" {
+		t.Error("Got result on closed channel " + result3) // not possible
+	}
+
+	noresults := FindAnagrams("Quixotic", make([]string, 0), testDict)
+	unresult, ok4 := <-noresults
+	if ok4 {
+		t.Error("Got anagram that shouldn't exist" + unresult)
+	}
+}
+
+func TestAnagramsAllValid(t *testing.T) {
+	// Every result must use exactly the same letters as the input.
+	input := "Mitch Patenaude"
+	inputRC := NewRuneCluster(input)
+
+	results := collectAll(FindAnagrams(input, nil, smallDict))
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for _, r := range results {
+		rc := NewRuneCluster(r)
+		if !rc.Equals(inputRC) {
+			t.Errorf("Result %q doesn't use the same letters as %q", r, input)
+		}
+	}
+}
+
+func TestAnagramsNoDuplicates(t *testing.T) {
+	input := "star eats"
+	results := collectAll(FindAnagrams(input, nil, mediumDict))
+
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if seen[r] {
+			t.Errorf("Duplicate result: %q", r)
+		}
+		seen[r] = true
+	}
+}
+
+func TestAnagramsEmptyInput(t *testing.T) {
+	results := collectAll(FindAnagrams("", nil, smallDict))
+	if len(results) != 0 {
+		t.Errorf("Expected 0 results for empty input, got %d", len(results))
+	}
+
+	results = collectAll(FindAnagrams("   ", nil, smallDict))
+	if len(results) != 0 {
+		t.Errorf("Expected 0 results for whitespace input, got %d", len(results))
+	}
+}
+
+func TestAnagramsNoMatch(t *testing.T) {
+	results := collectAll(FindAnagrams("zzzzz", nil, mediumDict))
+	if len(results) != 0 {
+		t.Errorf("Expected 0 results for unmatchable input, got %d", len(results))
+	}
+}
+
+func TestAnagramsSingleWord(t *testing.T) {
+	// "trace" and "crate" are anagrams of each other
+	dict := &Dictionary{Name: "test", Words: []string{"trace", "crate", "react"}}
+	results := collectAll(FindAnagrams("trace", nil, dict))
+
+	// Should find "crate" and "react" but not "trace" itself (same normalized form)
+	for _, r := range results {
+		rc := NewRuneCluster(r)
+		inputRC := NewRuneCluster("trace")
+		if !rc.Equals(inputRC) {
+			t.Errorf("Result %q doesn't match input letters", r)
+		}
+	}
+}
+
+func TestAnagramsWithInclusion(t *testing.T) {
+	input := "Mitch Patenaude"
+	include := []string{"death"}
+
+	results := collectAll(FindAnagrams(input, include, smallDict))
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result with inclusion 'death', got %d: %v", len(results), results)
+	}
+
+	if results[0] != "death pneumatic" {
+		t.Errorf("Expected 'death pneumatic', got %q", results[0])
+	}
+}
+
+func TestAnagramsMultiWord(t *testing.T) {
+	// Verify multi-word anagrams are found
+	input := "seat"
+	results := collectAll(FindAnagrams(input, nil, mediumDict))
+
+	// Should include multi-word results like "a set", "ate", "eat", "eta", "tea", etc.
+	if len(results) == 0 {
+		t.Error("Expected some results for 'seat'")
+	}
+
+	// Every result must be a valid anagram
+	inputRC := NewRuneCluster(input)
+	for _, r := range results {
+		rc := NewRuneCluster(r)
+		if !rc.Equals(inputRC) {
+			t.Errorf("Result %q doesn't use the same letters as %q", r, input)
+		}
+	}
+}
+
+func TestAnagramsRepeatedWords(t *testing.T) {
+	dict := &Dictionary{Name: "repeats", Words: []string{"ab", "c"}}
+	for _, workers := range []int{1, 4} {
+		results := collectAll(FindAnagramsWithOptions(context.Background(), "abab c", nil, dict, SearchOptions{Workers: workers}))
+		if len(results) != 1 || results[0] != "ab ab c" {
+			t.Errorf("Expected 'ab ab c' with %d workers, got %v", workers, results)
+		}
+	}
+}
+
+func TestAnagramsParallelOrder(t *testing.T) {
+	input := "star eats crate"
+	sequential := FindAnagramsWithOptions(context.Background(), input, nil, mediumDict, SearchOptions{Workers: 1})
+	var want []string
+	for r := range sequential {
+		want = append(want, r)
+	}
+
+	for _, workers := range []int{2, 3, 8} {
+		var got []string
+		for r := range FindAnagramsWithOptions(context.Background(), input, nil, mediumDict, SearchOptions{Workers: workers}) {
+			got = append(got, r)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("Ordered search with %d workers doesn't match sequential order", workers)
+		}
+	}
+
+	got := collectAll(FindAnagramsWithOptions(context.Background(), input, nil, mediumDict, SearchOptions{Workers: 4, Unordered: true}))
+	sort.Strings(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("Unordered search found %d results, expected %d", len(got), len(want))
+	}
+}
+
+func TestAnagramsParallelInclusion(t *testing.T) {
+	opts := SearchOptions{Workers: 4}
+	results := collectAll(FindAnagramsWithOptions(context.Background(), "Mitch Patenaude", []string{"death"}, smallDict, opts))
+
+	if len(results) != 1 || results[0] != "death pneumatic" {
+		t.Errorf("Expected 'death pneumatic', got %v", results)
+	}
+}
+
+func TestAnagramsConstraints(t *testing.T) {
+	input := "star eats crate"
+	all := collectAll(FindAnagrams(input, nil, mediumDict))
+
+	cases := []Constraints{
+		{MaxWords: 2},
+		{MinWords: 4},
+		{MinWords: 3, MaxWords: 3},
+		{MinWordLength: 4},
+		{MaxWordLength: 3},
+		{MinWords: 2, MaxWords: 3, MinWordLength: 3, MaxWordLength: 5},
+	}
+
+	for _, c := range cases {
+		var want []string
+		for _, r := range all {
+			words := strings.Fields(r)
+			ok := c.allowsCount(len(words))
+			for _, w := range words {
+				ok = ok && c.allowsLength(len(w))
+			}
+			if ok {
+				want = append(want, r)
+			}
+		}
+
+		for _, workers := range []int{1, 4} {
+			opts := SearchOptions{Workers: workers, Constraints: c}
+			got := collectAll(FindAnagramsWithOptions(context.Background(), input, nil, mediumDict, opts))
+			if !slices.Equal(got, want) {
+				t.Errorf("Constraints %+v with %d workers gave %d results, expected %d", c, workers, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestAnagramsConstraintsWithInclusion(t *testing.T) {
+	// The included phrase counts as words but isn't subject to length limits.
+	opts := SearchOptions{Constraints: Constraints{MaxWords: 2, MaxWordLength: 5}}
+	results := collectAll(FindAnagramsWithOptions(context.Background(), "Mitch Patenaude", []string{"pneumatic"}, smallDict, opts))
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results, got %v", results)
+	}
+
+	opts.Constraints = Constraints{MaxWords: 1}
+	results = collectAll(FindAnagramsWithOptions(context.Background(), "Mitch Patenaude", []string{"pneumatic"}, smallDict, opts))
+	if len(results) != 0 {
+		t.Errorf("Expected no results with one word allowed, got %v", results)
+	}
+}
+
+func TestAnagramsDeadEnds(t *testing.T) {
+	// Dead ends are only remembered for branches with plenty of words, so
+	// this needs a real dictionary.
+	mainDicts, _, err := ReadDictionaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := mainDicts[0]
+
+	cases := []struct {
+		input    string
+		included []string
+		opts     SearchOptions
+	}{
+		{"Karma Manager", nil, SearchOptions{Workers: 1}},
+		{"Karma Manager", nil, SearchOptions{Workers: 4}},
+		{"Karma Manager", nil, SearchOptions{Workers: 1, Constraints: Constraints{MinWords: 2, MaxWords: 3}}},
+		{"Karma Manager", nil, SearchOptions{Workers: 1, Constraints: Constraints{MaxLeftover: 1, MaxWords: 3}}},
+		{"Karma Manager", nil, SearchOptions{Workers: 1, MemoSize: 3}},
+		{"Mitch Patenaude", []string{"death"}, SearchOptions{Workers: 1, Constraints: Constraints{MaxWords: 3}}},
+	}
+
+	for _, c := range cases {
+		var want, got []string
+		off := c.opts
+		off.MemoSize = -1
+		for r := range FindAnagramsWithOptions(context.Background(), c.input, c.included, dict, off) {
+			want = append(want, r)
+		}
+		for r := range FindAnagramsWithOptions(context.Background(), c.input, c.included, dict, c.opts) {
+			got = append(got, r)
+		}
+		if len(want) == 0 || !slices.Equal(got, want) {
+			t.Errorf("%q with %+v gave %d results remembering dead ends, %d without", c.input, c.opts, len(got), len(want))
+		}
+	}
+}
+
+func TestDeadEndsLimit(t *testing.T) {
+	s := &searcher{opts: SearchOptions{MemoSize: 2}}
+	dead := s.newDeadEnds()
+	for _, letters := range []string{"star", "eats", "crate", "stare", "cats"} {
+		key, ok := s.deadEndKey(0, NewRuneCluster(letters))
+		if !ok {
+			t.Fatal("Expected a key")
+		}
+		dead.add(key, 10)
+		dead.add(key, 5)
+	}
+	if len(dead.keys) != 2 {
+		t.Errorf("Remembered %d dead ends, expected at most 2", len(dead.keys))
+	}
+
+	key, _ := s.deadEndKey(0, NewRuneCluster("star"))
+	if !dead.has(key, 5) || !dead.has(key, 7) || dead.has(key, 4) {
+		t.Error("A dead end should cover later starts only")
+	}
+
+	s.opts.MemoSize = -1
+	if s.newDeadEnds() != nil {
+		t.Error("Expected no dead ends with remembering turned off")
+	}
+}
+
+func TestFilterAnnotatedDict(t *testing.T) {
+	filtered, rc := FilterAnnotatedDict("cat", mediumDict)
+
+	// Every word in filtered must be a subset of "cat"
+	for _, dp := range filtered {
+		if !dp.cluster.SubSetOf(rc) {
+			t.Errorf("Filtered word %q is not a subset of 'cat'", dp.Word)
+		}
+	}
+
+	// "star" should not be in filtered (has 's' and 'r')
+	for _, dp := range filtered {
+		if dp.Word == "star" {
+			t.Error("'star' should not be in filtered dict for 'cat'")
+		}
+	}
+
+	// "act" and "cat" should be in filtered
+	found := map[string]bool{}
+	for _, dp := range filtered {
+		found[dp.Word] = true
+	}
+	if !found["act"] {
+		t.Error("'act' should be in filtered dict for 'cat'")
+	}
+	if !found["cat"] {
+		t.Error("'cat' should be in filtered dict for 'cat'")
+	}
+}
+
+func TestAnnotatedDictCache(t *testing.T) {
+	dict := &Dictionary{Name: "test", Words: []string{"foo", "bar", "baz"}}
+
+	ad1 := GetAnnotatedDict(dict)
+	ad2 := GetAnnotatedDict(dict)
+
+	if &ad1[0] != &ad2[0] {
+		t.Error("GetAnnotatedDict should return the same cached slice")
+	}
+}
+
+func TestAnnotatedDictConcurrent(t *testing.T) {
+	dict := &Dictionary{Name: "test", Words: []string{"foo", "bar", "baz"}}
+	other := &Dictionary{Name: "other", Words: []string{"cat", "act"}}
+
+	// Searches and merges on other goroutines share the cached annotations
+	// rather than racing to build them.
+	var wg sync.WaitGroup
+	annotations := make([]annotatedDict, 8)
+	for i := range annotations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				annotations[i] = GetAnnotatedDict(dict)
+			} else {
+				GetAnnotatedDict(other)
+				merged := MergeDictionaries(nil, dict, other)
+				annotations[i] = GetAnnotatedDict(dict)
+				if len(GetAnnotatedDict(merged)) != 5 {
+					t.Errorf("Expected 5 merged words, got %v", merged.Words)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, ad := range annotations {
+		if &ad[0] != &annotations[0][0] {
+			t.Error("Expected every goroutine to get the same cached slice")
+		}
+	}
+}
+
+func TestAnagramsOwnAlphabet(t *testing.T) {
+	spanish, _ := NewAlphabet("Spanish", []rune{'ñ'}, nil)
+	words := []string{"año", "ano", "o", "a", "ñ", "n"}
+	spanishDict := &Dictionary{Name: "spanish", Words: words, Alphabet: spanish}
+	latinDict := &Dictionary{Name: "latin", Words: words}
+
+	// Both searches run at once, each with its dictionary's alphabet.
+	spanishResults := FindAnagrams("año", nil, spanishDict)
+	latinResults := FindAnagrams("año", nil, latinDict)
+	for _, r := range collectAll(spanishResults) {
+		if spanish.Cluster(r)[spanish.Index('ñ')] != 1 {
+			t.Errorf("Spanish result %q should use the ñ", r)
+		}
+	}
+	if got := collectAll(latinResults); !slices.Contains(got, "ano") {
+		t.Errorf("Latin results %v should treat ñ as n", got)
+	}
+}
+
+// --- Benchmarks ---
+
+// loadUSDict loads the real US dictionary for benchmarks. Skips if not available.
+func loadUSDict(b *testing.B) *Dictionary {
+	b.Helper()
+	mainDicts, _, err := ReadDictionaries()
+	if err != nil || len(mainDicts) == 0 {
+		b.Skip("Could not load dictionaries")
+	}
+	return mainDicts[0]
+}
+
+func BenchmarkNewRuneCluster(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewRuneCluster("California Polytechnic University")
+	}
+}
+
+func BenchmarkSubSetOf(b *testing.B) {
+	rc1 := NewRuneCluster("California Polytechnic University")
+	rc2 := NewRuneCluster("polynomial")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rc2.SubSetOf(rc1)
+	}
+}
+
+func BenchmarkFilterSmallDict(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		mediumDict.annotated = nil
+		FilterAnnotatedDict("star eats", mediumDict)
+	}
+}
+
+func BenchmarkFilterSmallDictCached(b *testing.B) {
+	GetAnnotatedDict(mediumDict)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FilterAnnotatedDict("star eats", mediumDict)
+	}
+}
+
+func BenchmarkFilterRealDict(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict) // pre-cache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FilterAnnotatedDict("Karma Manager", dict)
+	}
+}
+
+func BenchmarkFilterRealDictLongInput(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FilterAnnotatedDict("California Polytechnic University", dict)
+	}
+}
+
+func BenchmarkFindAnagramsSmall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ch := FindAnagrams("Mitch Patenaude", nil, smallDict)
+		for range ch {
+		}
+	}
+}
+
+func BenchmarkFindAnagramsMedium(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ch := FindAnagrams("star eats", nil, mediumDict)
+		for range ch {
+		}
+	}
+}
+
+func BenchmarkFindAnagramsRealShort(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch := FindAnagrams("Karma Manager", nil, dict)
+		for range ch {
+		}
+	}
+}
+
+func BenchmarkFindAnagramsRealMedium(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch := FindAnagrams("Mitch Patenaude", nil, dict)
+		// Collect first 1000 results only to keep benchmark feasible
+		n := 0
+		for range ch {
+			n++
+			if n >= 1000 {
+				break
+			}
+		}
+	}
+}
+
+// searchVariants are the SearchOptions the Real* benchmarks compare.
+var searchVariants = []struct {
+	name string
+	opts SearchOptions
+}{
+	{"Sequential", SearchOptions{Workers: 1}},
+	{"Ordered", SearchOptions{}},
+	{"Unordered", SearchOptions{Unordered: true}},
+	{"SequentialNoMemo", SearchOptions{Workers: 1, MemoSize: -1}},
+}
+
+func BenchmarkFindAnagramsRealLong(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+
+	for _, v := range searchVariants {
+		b.Run(v.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ctx, cancel := context.WithCancel(context.Background())
+				ch := FindAnagramsWithOptions(ctx, "California Polytechnic", nil, dict, v.opts)
+				// Collect first 1000 results only
+				n := 0
+				for range ch {
+					n++
+					if n >= 1000 {
+						break
+					}
+				}
+				cancel()
+			}
+		})
+	}
+}
+
+func BenchmarkFindAnagramsRealExhaustive(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+
+	for _, v := range searchVariants {
+		b.Run(v.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ch := FindAnagramsWithOptions(context.Background(), "Karma Manager", nil, dict, v.opts)
+				for range ch {
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFindAnagramsRealTwoWords is dominated by the last word, which
+// is always looked up by its letters.
+func BenchmarkFindAnagramsRealTwoWords(b *testing.B) {
+	dict := loadUSDict(b)
+	GetAnnotatedDict(dict)
+	opts := SearchOptions{Workers: 1, Constraints: Constraints{MaxWords: 2}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range FindAnagramsWithOptions(context.Background(), "clint eastwood", nil, dict, opts) {
+		}
+	}
+}
+
+func TestFindAnagramsCommonWordsOnly(t *testing.T) {
+	dict, err := ParseDictionary("rich", []byte(`[
+		{"word": "rat", "frequency": 0.9},
+		{"word": "tar", "frequency": 0.6},
+		{"word": "art", "frequency": 0.1},
+		"tra"
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := SearchOptions{Constraints: Constraints{MinFrequency: CommonFrequency}}
+	found := make(map[string]bool)
+	for r := range FindAnagramsWithOptions(context.Background(), "rat", nil, dict, opts) {
+		found[r] = true
+	}
+
+	for _, word := range []string{"rat", "tar", "tra"} {
+		if !found[word] {
+			t.Errorf("Expected %q in common results", word)
+		}
+	}
+	if found["art"] {
+		t.Error("Uncommon word \"art\" should have been left out")
+	}
+
+	scorer := NewScorer()
+	scorer.Frequency = DictionaryFrequency(dict)
+	if scorer.Score("rat") <= scorer.Score("art") || scorer.Score("tra") <= scorer.Score("art") {
+		t.Error("Common words should score higher than obscure ones")
+	}
+	if DictionaryFrequency(mediumDict) != nil {
+		t.Error("Plain dictionary shouldn't provide frequencies")
+	}
+}
+
+func TestFindPartialAnagrams(t *testing.T) {
+	dict := &Dictionary{Name: "tiny", Words: []string{"rat", "tar", "at", "star", "cats"}}
+
+	opts := SearchOptions{Constraints: Constraints{MaxLeftover: 1}}
+	found := make(map[string]string)
+	for pa := range FindPartialAnagrams(context.Background(), "start a", nil, dict, opts) {
+		if pa.Leftover.Size() > 1 {
+			t.Errorf("%q leaves %q, more than allowed", pa.Anagram, pa.Leftover)
+		}
+		found[pa.Anagram] = pa.Leftover.String()
+	}
+	expected := map[string]string{"star at": "", "tar at": "s", "rat at": "s"}
+	for anagram, leftover := range expected {
+		if got, ok := found[anagram]; !ok || got != leftover {
+			t.Errorf("Expected %q leaving %q, got %q (%v)", anagram, leftover, got, ok)
+		}
+	}
+	if _, ok := found["star"]; ok {
+		t.Error("\"star\" leaves two letters and shouldn't be allowed")
+	}
+
+	// An explicit leftover is never used, and is the whole remainder.
+	opts = SearchOptions{Constraints: Constraints{Leftover: "s"}}
+	count := 0
+	for pa := range FindPartialAnagrams(context.Background(), "start a", nil, dict, opts) {
+		count += 1
+		if pa.Leftover.String() != "s" {
+			t.Errorf("%q leaves %q, expected \"s\"", pa.Anagram, pa.Leftover)
+		}
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results leaving \"s\", got %d", count)
+	}
+	for range FindAnagramsWithOptions(context.Background(), "start", nil, dict, SearchOptions{Constraints: Constraints{Leftover: "z"}}) {
+		t.Error("Leftover that isn't in the input should give no results")
+	}
+}
+
+func TestFindPartialAnagramsParallelMatchesSequential(t *testing.T) {
+	c := Constraints{MaxLeftover: 2, MaxWords: 3}
+	sequential := collectAll(FindAnagramsWithOptions(context.Background(), "star eats", nil, mediumDict, SearchOptions{Workers: 1, Constraints: c}))
+	parallel := collectAll(FindAnagramsWithOptions(context.Background(), "star eats", nil, mediumDict, SearchOptions{Workers: 4, Constraints: c}))
+
+	if !slices.Equal(sequential, parallel) {
+		t.Errorf("Parallel partial search differs: %d vs %d results", len(sequential), len(parallel))
+	}
+	if len(sequential) == 0 {
+		t.Error("Expected partial anagrams")
+	}
+}
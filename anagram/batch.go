@@ -0,0 +1,199 @@
+package anagram
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// default_batch_limit is how many anagrams RunBatch keeps for each input
+// unless told otherwise.
+const default_batch_limit = 10
+
+// BatchFormats are the report formats WriteBatchReport can write.
+var BatchFormats = []string{"text", "csv", "json"}
+
+// BatchOptions controls RunBatch. Every input is searched with the same
+// dictionary, options and included phrases.
+type BatchOptions struct {
+	Search    SearchOptions
+	Include   []string
+	Limit     int           // anagrams kept for each input, default_batch_limit if 0
+	TimeLimit time.Duration // the longest to search each input for, 0 for no limit
+	Scorer    *Scorer       // keeps the best anagrams rather than the first found, if set
+}
+
+// BatchResult is the anagrams RunBatch found for one input, best first if
+// they were scored.
+type BatchResult struct {
+	Input    string    `json:"input"`
+	Anagrams []string  `json:"anagrams"`
+	Scores   []float64 `json:"scores,omitempty"`
+	TimedOut bool      `json:"timed_out,omitempty"` // the time limit cut the search short
+}
+
+// ReadBatchInputs reads the inputs for RunBatch, one per line, or from the
+// first column of CSV with a header of "input" or "name" skipped. Blank
+// inputs are left out.
+func ReadBatchInputs(r io.Reader, isCSV bool) ([]string, error) {
+	inputs := make([]string, 0)
+	if !isCSV {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				inputs = append(inputs, line)
+			}
+		}
+		return inputs, scanner.Err()
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		input := strings.TrimSpace(record[0])
+		if i == 0 && (strings.EqualFold(input, "input") || strings.EqualFold(input, "name")) {
+			continue
+		}
+		if input != "" {
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs, nil
+}
+
+// RunBatch finds anagrams for each of inputs in turn, calling progress, if
+// set, after each one. Without a Scorer an input's search stops once it has
+// Limit anagrams; with one it keeps the best Limit of up to
+// max_ranked_scanned. The input itself is never one of its anagrams. If ctx
+// is cancelled it returns the inputs finished so far and ctx.Err().
+func RunBatch(ctx context.Context, inputs []string, dictionary *Dictionary, opts BatchOptions, progress func(done, total int)) ([]BatchResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = default_batch_limit
+	}
+
+	results := make([]BatchResult, 0, len(inputs))
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, runBatchInput(ctx, input, dictionary, opts, limit))
+		if progress != nil {
+			progress(i+1, len(inputs))
+		}
+	}
+	return results, ctx.Err()
+}
+
+// runBatchInput finds the anagrams of one input for RunBatch.
+func runBatchInput(ctx context.Context, input string, dictionary *Dictionary, opts BatchOptions, limit int) BatchResult {
+	var searchCtx context.Context
+	var cancel context.CancelFunc
+	if opts.TimeLimit > 0 {
+		searchCtx, cancel = context.WithTimeout(ctx, opts.TimeLimit)
+	} else {
+		searchCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	result := BatchResult{Input: input, Anagrams: make([]string, 0, limit)}
+	seen := NewDeduper(input, dictionary.alphabet())
+	topK := NewTopK(limit)
+	scanned := 0
+	for anagram := range FindAnagramsWithOptions(searchCtx, input, opts.Include, dictionary, opts.Search) {
+		if !seen.IsNew(anagram) {
+			continue
+		}
+
+		if opts.Scorer == nil {
+			result.Anagrams = append(result.Anagrams, anagram)
+			if len(result.Anagrams) >= limit {
+				cancel()
+				break
+			}
+			continue
+		}
+		topK.Add(anagram, opts.Scorer.Score(anagram))
+		scanned += 1
+		if scanned >= max_ranked_scanned {
+			cancel()
+			break
+		}
+	}
+	result.TimedOut = ctx.Err() == nil && errors.Is(searchCtx.Err(), context.DeadlineExceeded)
+
+	for _, sr := range topK.Sorted() {
+		result.Anagrams = append(result.Anagrams, sr.Result)
+		result.Scores = append(result.Scores, sr.Score)
+	}
+	return result
+}
+
+// WriteBatchReport writes the results of RunBatch in one of BatchFormats.
+// The text report lists each input with its anagrams under it, the CSV one
+// has a row for each anagram and the JSON one is an array of BatchResults.
+func WriteBatchReport(w io.Writer, results []BatchResult, format string) error {
+	switch format {
+	case "text":
+		bw := bufio.NewWriter(w)
+		for i, result := range results {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			bw.WriteString(result.Input + "\n")
+			for _, anagram := range result.Anagrams {
+				bw.WriteString("    " + UnmarkSpaces(anagram) + "\n")
+			}
+			if len(result.Anagrams) == 0 {
+				bw.WriteString("    (no anagrams)\n")
+			}
+			if result.TimedOut {
+				bw.WriteString("    (out of time)\n")
+			}
+		}
+		return bw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"input", "rank", "anagram", "score"})
+		for _, result := range results {
+			for i, anagram := range result.Anagrams {
+				score := ""
+				if i < len(result.Scores) {
+					score = strconv.FormatFloat(result.Scores[i], 'f', 2, 64)
+				}
+				cw.Write([]string{result.Input, strconv.Itoa(i + 1), UnmarkSpaces(anagram), score})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		unmarked := make([]BatchResult, len(results))
+		for i, result := range results {
+			result.Anagrams = make([]string, len(results[i].Anagrams))
+			for j, anagram := range results[i].Anagrams {
+				result.Anagrams[j] = UnmarkSpaces(anagram)
+			}
+			unmarked[i] = result
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(unmarked)
+	default:
+		return fmt.Errorf("Unknown report format %q", format)
+	}
+}
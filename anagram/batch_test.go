@@ -0,0 +1,126 @@
+package anagram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadBatchInputs(t *testing.T) {
+	inputs, err := ReadBatchInputs(strings.NewReader("Karma Manager\n\n  star eats crate  \nPatenaude, Mitch\n"), false)
+	if err != nil || !slices.Equal(inputs, []string{"Karma Manager", "star eats crate", "Patenaude, Mitch"}) {
+		t.Errorf("Read lines as %q and %v", inputs, err)
+	}
+
+	inputs, err = ReadBatchInputs(strings.NewReader("Name,Company\nKarma Manager,Acme\n\"Patenaude, Mitch\",\n,Nobody\n"), true)
+	if err != nil || !slices.Equal(inputs, []string{"Karma Manager", "Patenaude, Mitch"}) {
+		t.Errorf("Read CSV as %q and %v", inputs, err)
+	}
+
+	if _, err := ReadBatchInputs(strings.NewReader("\"unfinished\n"), true); err == nil {
+		t.Error("Expected an error reading bad CSV")
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	inputs := []string{"star eats crate", "cat", "zzz"}
+	var done []int
+	results, err := RunBatch(context.Background(), inputs, mediumDict, BatchOptions{Limit: 5}, func(d, total int) {
+		if total != len(inputs) {
+			t.Errorf("Expected a total of %d, got %d", len(inputs), total)
+		}
+		done = append(done, d)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(done, []int{1, 2, 3}) {
+		t.Errorf("Expected progress after each input, got %v", done)
+	}
+	if len(results) != 3 || len(results[0].Anagrams) != 5 || len(results[2].Anagrams) != 0 {
+		t.Fatalf("Unexpected results %+v", results)
+	}
+	if !slices.Equal(results[1].Anagrams, []string{"act"}) {
+		t.Errorf("Expected act, leaving out cat itself, got %v", results[1].Anagrams)
+	}
+	for _, result := range results {
+		for _, anagram := range result.Anagrams {
+			if left := LatinAlphabet.Leftover(result.Input, anagram); left == nil || !left.IsEmpty() {
+				t.Errorf("%q isn't an anagram of %q", anagram, result.Input)
+			}
+		}
+	}
+
+	// Scored, the best come first.
+	scorer := NewScorer()
+	results, _ = RunBatch(context.Background(), inputs[:1], mediumDict, BatchOptions{Limit: 5, Scorer: scorer}, nil)
+	all := collectAll(FindAnagrams("star eats crate", nil, mediumDict))
+	slices.SortFunc(all, func(a, b string) int {
+		if sa, sb := scorer.Score(a), scorer.Score(b); sa != sb {
+			if sa > sb {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	if !slices.Equal(results[0].Anagrams, all[:5]) || len(results[0].Scores) != 5 {
+		t.Errorf("Expected the best five %v, got %v", all[:5], results[0].Anagrams)
+	}
+
+	results, _ = RunBatch(context.Background(), []string{"the quick brown fox jumps over the lazy dog"}, mediumDict, BatchOptions{Scorer: scorer, TimeLimit: time.Nanosecond}, nil)
+	if !results[0].TimedOut {
+		t.Error("Expected the search to run out of time")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, err := RunBatch(ctx, inputs, mediumDict, BatchOptions{}, nil); err != context.Canceled || len(results) != 0 {
+		t.Errorf("Expected nothing and context.Canceled, got %v and %v", results, err)
+	}
+}
+
+func TestWriteBatchReport(t *testing.T) {
+	results := []BatchResult{
+		{Input: "Karma Manager", Anagrams: []string{"anagram maker", MarkSpaces("karma manager") + " x"}, Scores: []float64{3, 1.5}},
+		{Input: "zzz", Anagrams: []string{}, TimedOut: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBatchReport(&buf, results, "text"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Karma Manager\n    anagram maker\n    karma manager x\n\nzzz\n    (no anagrams)\n    (out of time)\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteBatchReport(&buf, results, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	want = "input,rank,anagram,score\nKarma Manager,1,anagram maker,3.00\nKarma Manager,2,karma manager x,1.50\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteBatchReport(&buf, results, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var read []BatchResult
+	if err := json.Unmarshal(buf.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0].Anagrams[1] != "karma manager x" || !read[1].TimedOut {
+		t.Errorf("Unexpected JSON %s", buf.String())
+	}
+
+	if err := WriteBatchReport(&buf, results, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,176 @@
+package anagram
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// maxLetters is the number of distinct letters a RuneCluster can count: the
+// 26 basic letters plus a few slots for alphabets that keep accented letters
+// apart (see Alphabet).
+const maxLetters = 32
+
+// RuneCluster is a fixed-size array of letter frequencies, indexed by
+// Alphabet.Index, so only clusters made with the same alphabet can be
+// compared. Using an array instead of map[rune]int avoids map allocation
+// overhead and is cache-friendly for the tight loops in anagram search.
+type RuneCluster [maxLetters]int
+
+// NewRuneCluster counts the letters of input with LatinAlphabet. Use
+// Alphabet.Cluster to count them the way a dictionary does.
+func NewRuneCluster(input string) *RuneCluster {
+	return LatinAlphabet.Cluster(input)
+}
+
+// Cluster counts the letters of input, folding the ones without a slot of
+// their own.
+func (a *Alphabet) Cluster(input string) *RuneCluster {
+	var rc RuneCluster
+	for _, r := range input {
+		if r >= 'a' && r <= 'z' {
+			rc[r-'a']++
+		} else if unicode.IsLetter(r) {
+			for _, f := range a.Fold(r) {
+				if idx := a.Index(f); idx >= 0 {
+					rc[idx]++
+				}
+			}
+		}
+	}
+	return &rc
+}
+
+// Count returns how many of the letter r there are. Letters beyond a-z have
+// slots that depend on the alphabet, so look those up with Alphabet.Index.
+func (rc *RuneCluster) Count(r rune) int {
+	idx := LatinAlphabet.Index(unicode.ToLower(r))
+	if idx < 0 {
+		return 0
+	}
+	return rc[idx]
+}
+
+func (rc *RuneCluster) Has(r rune) bool {
+	return rc.Count(r) > 0
+}
+
+func (rc *RuneCluster) SubSetOf(other *RuneCluster) bool {
+	for i := range rc {
+		if rc[i] > other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (rc *RuneCluster) Equals(other *RuneCluster) bool {
+	return *rc == *other
+}
+
+func (rc *RuneCluster) Minus(other *RuneCluster) (*RuneCluster, error) {
+	var result RuneCluster
+	for i := range rc {
+		diff := rc[i] - other[i]
+		if diff < 0 {
+			return nil, errors.New("Not a superset of other cluster")
+		}
+		result[i] = diff
+	}
+	return &result, nil
+}
+
+func (rc *RuneCluster) Add(other *RuneCluster) {
+	for i := range rc {
+		rc[i] += other[i]
+	}
+}
+
+func (rc *RuneCluster) IsEmpty() bool {
+	for i := range rc {
+		if rc[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns the total number of letters in the cluster.
+func (rc *RuneCluster) Size() int {
+	size := 0
+	for i := range rc {
+		size += rc[i]
+	}
+	return size
+}
+
+// addTimes adds n copies of other's letters to rc.
+func (rc *RuneCluster) addTimes(other *RuneCluster, n int) {
+	for i := range rc {
+		rc[i] += n * other[i]
+	}
+}
+
+// timesIn is how many copies of rc fit in other at once.
+func (rc *RuneCluster) timesIn(other *RuneCluster) int {
+	times := math.MaxInt
+	for i := range rc {
+		if rc[i] > 0 {
+			times = min(times, other[i]/rc[i])
+		}
+	}
+	if times == math.MaxInt {
+		return 0
+	}
+	return times
+}
+
+// shortfall returns how many letters of rc are missing from other. It's zero
+// exactly when rc is a subset of other.
+func (rc *RuneCluster) shortfall(other *RuneCluster) int {
+	missing := 0
+	for i := range rc {
+		if rc[i] > other[i] {
+			missing += rc[i] - other[i]
+		}
+	}
+	return missing
+}
+
+// String spells out the letters a-z in the cluster, see Spell.
+func (rc *RuneCluster) String() string {
+	return rc.Spell(LatinAlphabet)
+}
+
+// Spell spells out the letters in the cluster in alphabet order, e.g.
+// "aest" for "seat".
+func (rc *RuneCluster) Spell(a *Alphabet) string {
+	b := strings.Builder{}
+	for i, n := range rc {
+		var r rune
+		if i < 26 {
+			r = rune('a' + i)
+		} else if i-26 < len(a.Extra) {
+			r = a.Extra[i-26]
+		} else {
+			continue
+		}
+		for j := 0; j < n; j++ {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// signature hashes the letter counts, so clusters with the same letters
+// have the same signature. Different clusters can collide, so compare them
+// with Equals before relying on a match.
+func (rc *RuneCluster) signature() uint64 {
+	h := uint64(14695981039346656037) // FNV-1a offset basis
+	for _, n := range rc {
+		h ^= uint64(n)
+		h *= 1099511628211
+	}
+	return h
+}
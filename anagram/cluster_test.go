@@ -0,0 +1,144 @@
+package anagram
+
+import (
+	"testing"
+)
+
+func TestRuneCluster(t *testing.T) {
+	abcde := NewRuneCluster("abcde")
+	abcd := NewRuneCluster("cba D")
+	other_abcd := NewRuneCluster("d C B a")
+
+	if !abcd.Has('d') {
+		t.Error("Missing value 'd'")
+	}
+
+	if abcde.SubSetOf(abcd) {
+		t.Error("abcde should not be a subset of abcd")
+	}
+
+	if !abcd.SubSetOf(abcde) {
+		t.Error("abcd should be a subset of abcde")
+	}
+
+	if abcd.Equals(abcde) {
+		t.Error("abcd != abcde")
+	}
+
+	if !abcd.Equals(other_abcd) {
+		t.Error("These should be equal")
+	}
+
+	result, err := abcde.Minus(abcd)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !result.Has('e') {
+		t.Error("'e' should be in abcde-abcd")
+	}
+
+	if result.Has('d') {
+		t.Error("'d' should not be in abcde-abcd")
+	}
+
+	result, err = abcd.Minus(other_abcd)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if !result.IsEmpty() {
+		t.Error("Result of abcd-abcd should be empty")
+	}
+}
+
+func TestRuneClusterFolding(t *testing.T) {
+	if !NewRuneCluster("Café").Equals(NewRuneCluster("face")) {
+		t.Error("é should fold to e")
+	}
+
+	if !NewRuneCluster("Straße").Equals(NewRuneCluster("strasse")) {
+		t.Error("ß should fold to ss")
+	}
+
+	if !NewRuneCluster("Æsop").Equals(NewRuneCluster("aesop")) {
+		t.Error("Æ should fold to ae")
+	}
+
+	if !NewRuneCluster("naïve").Has('i') {
+		t.Error("Missing folded value 'i'")
+	}
+
+	if !NewRuneCluster("日本").IsEmpty() {
+		t.Error("Letters outside the alphabet should be ignored")
+	}
+}
+
+func TestExtendedAlphabet(t *testing.T) {
+	spanish, err := NewAlphabet("Spanish", []rune{'ñ'}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spanish.Cluster("año").Equals(spanish.Cluster("ano")) {
+		t.Error("ñ should be distinct from n")
+	}
+
+	if spanish.Cluster("AÑO")[spanish.Index('ñ')] != 1 {
+		t.Error("Missing extended value 'ñ'")
+	}
+
+	if got := spanish.Cluster("año").Spell(spanish); got != "aoñ" {
+		t.Errorf("Expected aoñ, got %q", got)
+	}
+
+	if !spanish.Cluster("canción").Equals(spanish.Cluster("cancion")) {
+		t.Error("ó should still fold to o")
+	}
+
+	// Other alphabets are unaffected.
+	if !NewRuneCluster("año").Equals(NewRuneCluster("ano")) {
+		t.Error("The Latin alphabet should fold ñ to n")
+	}
+
+	tooMany := []rune("àáâãäåāă")
+	if _, err := NewAlphabet("Too many", tooMany, nil); err == nil {
+		t.Error("Expected an error for too many extra letters")
+	}
+}
+
+func TestAlphabetFolds(t *testing.T) {
+	german, _ := NewAlphabet("German", []rune{'ä', 'ö', 'ü'}, map[rune]string{'ß': "sz"})
+
+	if !german.Cluster("Maß").Equals(german.Cluster("masz")) {
+		t.Error("Alphabet folds should override the default folding")
+	}
+
+	if german.Normalize("Über Maß") != "masz über" {
+		t.Errorf("Unexpected normalization %q", german.Normalize("Über Maß"))
+	}
+
+	if Normalize("Über Maß") != "mass uber" {
+		t.Errorf("Unexpected Latin normalization %q", Normalize("Über Maß"))
+	}
+
+	if german.Covers("日本") || !german.Covers("Müller") {
+		t.Error("Covers() gave the wrong answer")
+	}
+}
+
+func TestRuneClusterTimesIn(t *testing.T) {
+	word := NewRuneCluster("ab")
+	if n := word.timesIn(NewRuneCluster("aabbbc")); n != 2 {
+		t.Errorf("Expected ab to fit twice, got %d", n)
+	}
+	if n := word.timesIn(NewRuneCluster("bc")); n != 0 {
+		t.Errorf("Expected ab not to fit, got %d", n)
+	}
+
+	var sum RuneCluster
+	sum.addTimes(word, 3)
+	if !sum.Equals(NewRuneCluster("aaabbb")) {
+		t.Errorf("Expected aaabbb, got %s", sum.String())
+	}
+}
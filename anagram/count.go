@@ -0,0 +1,237 @@
+package anagram
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+)
+
+// max_count_cells bounds the table CountAnagrams fills in, about 8 bytes a
+// cell.
+const max_count_cells = 1 << 22
+
+var ErrTooManyLetters = errors.New("Too many letters to count the anagrams")
+
+// ErrOnlyAnagrams is returned when asked to count the results of another
+// kind of wordplay.
+var ErrOnlyAnagrams = errors.New("Only anagrams can be counted")
+
+// CountAnagrams counts the results FindAnagramsWithOptions would produce for
+// the same arguments, without making them. Each result is a different
+// bunch of words, so it counts, for every part of the input's letters, the
+// bunches of words that use up exactly that part, adding the words one at a
+// time. That takes a table the size of the number of ways to pick some of
+// the input's letters, which is far quicker than finding every result. If
+// the table would be too big it returns ErrTooManyLetters. Counts too big
+// for an int are reported as math.MaxInt. If ctx is cancelled it gives up
+// and returns ctx.Err(). Results matching a Pattern can't be counted, so
+// with one it returns ErrCantCountPatterns.
+//
+// Only opts.Constraints matters.
+func CountAnagrams(ctx context.Context, input string, include []string, dictionary *Dictionary, opts SearchOptions) (int, error) {
+	if strings.TrimSpace(input) == "" {
+		return 0, nil
+	}
+
+	if opts.Constraints.Pattern != "" {
+		return 0, ErrCantCountPatterns
+	}
+
+	s := newSearcher(ctx, dictionary, opts)
+	filtered, target, ok := s.prepare(input, dictionary)
+	if !ok {
+		return 0, nil
+	}
+	t, err := newCountTable(target, opts.Constraints)
+	if err != nil {
+		return 0, err
+	}
+	for _, dp := range filtered {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		t.add(dp.cluster)
+	}
+
+	total := 0
+	s.eachStart(include, filtered, target, func(phrase string, words int, target *RuneCluster, dict annotatedDict) {
+		total = addCounts(total, t.results(words, target))
+	})
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// addCounts adds without overflowing.
+func addCounts(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// countTable holds, for every part of a target's letters and number of
+// words, how many bunches of the words added so far make it. A part is
+// numbered by counting its letters in mixed radix, so taking a word away
+// is subtracting the word's number.
+type countTable struct {
+	c       Constraints
+	target  *RuneCluster
+	stride  [maxLetters]int
+	cells   int // number of parts of target
+	words   int // word counts kept for each part
+	counts  []int
+	letters []int // the letters of target with any count, in stride order
+}
+
+func newCountTable(target *RuneCluster, c Constraints) (*countTable, error) {
+	// Word counts only matter when they're limited. Without a maximum, every
+	// count from MinWords up is kept together.
+	words := 1
+	if c.MaxWords > 0 {
+		words = c.MaxWords + 1
+	} else if c.MinWords > 0 {
+		words = c.MinWords + 1
+	}
+
+	t := &countTable{c: c, target: target, cells: 1, words: words}
+	for i, n := range target {
+		if n == 0 {
+			continue
+		}
+		t.letters = append(t.letters, i)
+		t.stride[i] = t.cells
+		if t.cells > max_count_cells/(n+1) {
+			return nil, ErrTooManyLetters
+		}
+		t.cells *= n + 1
+	}
+	if t.cells > max_count_cells/words {
+		return nil, ErrTooManyLetters
+	}
+
+	t.counts = make([]int, t.cells*words)
+	t.counts[0] = 1 // no words make no letters
+	return t, nil
+}
+
+// index is the number of the part of target given by rc.
+func (t *countTable) index(rc *RuneCluster) int {
+	index := 0
+	for _, i := range t.letters {
+		index += rc[i] * t.stride[i]
+	}
+	return index
+}
+
+// add counts the bunches that can use word as many times as they like, as
+// well as the words already added. Parts are visited so that a part with
+// the word taken away is always updated before the part itself.
+func (t *countTable) add(word *RuneCluster) {
+	offset := t.index(word)
+	if offset == 0 {
+		return // a word without letters would make endless bunches
+	}
+
+	// An odometer over the parts with at least word's letters.
+	var part RuneCluster
+	for _, i := range t.letters {
+		part[i] = word[i]
+	}
+	index := offset
+	for {
+		from, to := (index-offset)*t.words, index*t.words
+		for w := 1; w < t.words; w++ {
+			t.counts[to+w] = addCounts(t.counts[to+w], t.counts[from+w-1])
+		}
+		if t.words > 1 && t.c.MaxWords <= 0 {
+			// The last slot holds MinWords words or more.
+			last := t.words - 1
+			t.counts[to+last] = addCounts(t.counts[to+last], t.counts[from+last])
+		} else if t.words == 1 {
+			t.counts[to] = addCounts(t.counts[to], t.counts[from])
+		}
+
+		k := 0
+		for ; k < len(t.letters); k++ {
+			i := t.letters[k]
+			if part[i] < t.target[i] {
+				part[i] += 1
+				index += t.stride[i]
+				break
+			}
+			index -= (part[i] - word[i]) * t.stride[i]
+			part[i] = word[i]
+		}
+		if k == len(t.letters) {
+			return
+		}
+	}
+}
+
+// results is how many phrases use up target after a start of the given
+// number of words, or leave no more than MaxLeftover of its letters.
+func (t *countTable) results(words int, target *RuneCluster) int {
+	total := 0
+	t.ends(words, target, func(part *RuneCluster, slot int, n int) {
+		total = addCounts(total, n)
+	})
+	return total
+}
+
+// ends calls found with each part of target that phrases can use, after a
+// start of the given number of words, along with each allowed slot of word
+// counts and the number of bunches of words in it. Without MaxLeftover the
+// only part is target itself.
+func (t *countTable) ends(words int, target *RuneCluster, found func(part *RuneCluster, slot int, n int)) {
+	end := func(part *RuneCluster) {
+		index := t.index(part)
+		if index == 0 && words == 0 {
+			return // only no words at all make no letters, and that's no phrase
+		}
+		for w := 0; w < t.words; w++ {
+			if n := t.counts[index*t.words+w]; n > 0 && t.allowsCount(words, w) {
+				found(part, w, n)
+			}
+		}
+	}
+
+	if t.c.MaxLeftover <= 0 {
+		end(target)
+		return
+	}
+
+	// Every part of target short by no more than MaxLeftover letters.
+	var part RuneCluster
+	size := 0
+	for {
+		if target.Size()-size <= t.c.MaxLeftover {
+			end(&part)
+		}
+		k := 0
+		for ; k < len(t.letters); k++ {
+			i := t.letters[k]
+			if part[i] < target[i] {
+				part[i] += 1
+				size += 1
+				break
+			}
+			size -= part[i]
+			part[i] = 0
+		}
+		if k == len(t.letters) {
+			return
+		}
+	}
+}
+
+// allowsCount reports whether the words of a start plus those in the given
+// slot are allowed.
+func (t *countTable) allowsCount(words, slot int) bool {
+	if t.c.MaxWords <= 0 && slot == t.words-1 {
+		return t.c.allowsCount(max(words+slot, t.c.MinWords)) // MinWords or more
+	}
+	return t.c.allowsCount(words + slot)
+}
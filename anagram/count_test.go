@@ -0,0 +1,65 @@
+package anagram
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCountAnagrams(t *testing.T) {
+	cases := []struct {
+		input    string
+		included []string
+		c        Constraints
+	}{
+		{"star eats crate", nil, Constraints{}},
+		{"star eats crate", nil, Constraints{MaxWords: 3}},
+		{"star eats crate", nil, Constraints{MinWords: 4}},
+		{"star eats crate", nil, Constraints{MinWords: 2, MaxWords: 3, MinWordLength: 3}},
+		{"star eats crate", nil, Constraints{MaxLeftover: 2}},
+		{"star eats crate", nil, Constraints{MaxLeftover: 1, MaxWords: 2}},
+		{"star eats crate", nil, Constraints{Leftover: "ae"}},
+		{"star eats crate", []string{"cat", "rat"}, Constraints{}},
+		{"star eats crate", []string{"cat", "rat"}, Constraints{MaxWords: 4, MaxLeftover: 1}},
+		{"Mitch Patenaude", []string{"death"}, Constraints{}},
+		{"cat", []string{"cat"}, Constraints{}},
+		{"", nil, Constraints{}},
+	}
+
+	for _, c := range cases {
+		dict := mediumDict
+		if c.input == "Mitch Patenaude" {
+			dict = smallDict
+		}
+		want := len(collectAll(FindAnagramsWithOptions(context.Background(), c.input, c.included, dict, SearchOptions{Constraints: c.c})))
+		got, err := CountAnagrams(context.Background(), c.input, c.included, dict, SearchOptions{Constraints: c.c})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Counted %d anagrams of %q with %v and %+v, the search found %d", got, c.input, c.included, c.c, want)
+		}
+	}
+}
+
+func TestCountAnagramsErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CountAnagrams(ctx, "star eats crate", nil, mediumDict, SearchOptions{}); err != context.Canceled {
+		t.Errorf("Expected a cancelled count, got %v", err)
+	}
+
+	long := "the quick brown fox jumps over the lazy dog and the cat"
+	if _, err := CountAnagrams(context.Background(), long, nil, mediumDict, SearchOptions{}); !errors.Is(err, ErrTooManyLetters) {
+		t.Errorf("Expected ErrTooManyLetters, got %v", err)
+	}
+}
+
+func TestAddCounts(t *testing.T) {
+	if addCounts(2, 3) != 5 {
+		t.Error("2 + 3 != 5")
+	}
+	if n := addCounts(1<<62, 1<<62); n != 1<<63-1 {
+		t.Errorf("Expected the sum to stop at the biggest int, got %d", n)
+	}
+}
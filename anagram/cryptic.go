@@ -0,0 +1,193 @@
+package anagram
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// min_shared_letters is the shortest run of letters that fodder can't share
+// with its answer. A solver spots a longer one straight away.
+const min_shared_letters = 4
+
+// Enumeration is the letter count of each word of a crossword answer, as
+// in "(5,4)".
+type Enumeration []int
+
+// ParseEnumeration reads an enumeration such as "(5,4)" or "5-4". The
+// brackets are optional and the counts can be separated by commas, hyphens
+// or spaces.
+func ParseEnumeration(text string) (Enumeration, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "("), ")")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '-' || r == ' '
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("Enumeration is empty")
+	}
+
+	enum := make(Enumeration, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n <= 0 {
+			return nil, errors.New("Enumeration \"" + text + "\" should be letter counts like (5,4)")
+		}
+		enum[i] = n
+	}
+	return enum, nil
+}
+
+func (e Enumeration) String() string {
+	counts := make([]string, len(e))
+	for i, n := range e {
+		counts[i] = strconv.Itoa(n)
+	}
+	return "(" + strings.Join(counts, ",") + ")"
+}
+
+// Letters is the total letter count.
+func (e Enumeration) Letters() int {
+	total := 0
+	for _, n := range e {
+		total += n
+	}
+	return total
+}
+
+// ParseClue splits the input of a CrypticMode search into the answer and
+// its enumeration, so "karma manager (5,7)" is "karma manager" and (5,7).
+// Without an enumeration it's the answer's word lengths. An answer written
+// as one run of letters is split up to match the enumeration, and it's an
+// error if the letter counts don't agree. Letters are counted with alphabet,
+// nil meaning LatinAlphabet.
+func ParseClue(input string, alphabet *Alphabet) (string, Enumeration, error) {
+	if alphabet == nil {
+		alphabet = LatinAlphabet
+	}
+
+	answer := strings.TrimSpace(input)
+	var enum Enumeration
+	if open := strings.LastIndex(answer, "("); open >= 0 && strings.HasSuffix(answer, ")") {
+		var err error
+		enum, err = ParseEnumeration(answer[open:])
+		if err != nil {
+			return "", nil, err
+		}
+		answer = strings.TrimSpace(answer[:open])
+	}
+
+	words := strings.Fields(answer)
+	if len(words) == 0 {
+		return "", nil, errors.New("There's no answer to find fodder for")
+	}
+	lengths := make(Enumeration, len(words))
+	for i, word := range words {
+		lengths[i] = len([]rune(alphabet.letterString(word)))
+	}
+	if enum == nil {
+		return answer, lengths, nil
+	}
+
+	if lengths.Letters() != enum.Letters() {
+		return "", nil, fmt.Errorf("The answer has %d letters but %v has %d", lengths.Letters(), enum, enum.Letters())
+	}
+	if len(words) == 1 && len(enum) > 1 {
+		letters := []rune(alphabet.letterString(answer))
+		split := make([]string, len(enum))
+		for i, n := range enum {
+			split[i] = string(letters[:n])
+			letters = letters[n:]
+		}
+		answer = strings.Join(split, " ")
+	}
+	return answer, enum, nil
+}
+
+// FindFodder streams anagram fodder for a cryptic crossword answer, the
+// phrases a setter could use in the clue with an anagram indicator. The
+// input is the answer, optionally with its enumeration, see ParseClue.
+// Fodder that gives the answer away is left out: any that repeats one of
+// the answer's words of min_hidden_letters or more, or shares a run of
+// min_shared_letters with it.
+func FindFodder(ctx context.Context, input string, include []string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	answer, _, err := ParseClue(input, dictionary.alphabet())
+	if err != nil {
+		log.Println(err)
+		answer = ""
+	}
+
+	return startWordplay(ctx, input, dictionary, opts, func(s *searcher, output chan<- string) {
+		if answer == "" {
+			return
+		}
+		for fodder := range FindAnagramsWithOptions(ctx, answer, include, dictionary, opts) {
+			if revealsAnswer(answer, fodder, s.alphabet) {
+				continue
+			}
+			s.send(fodder, output)
+		}
+	})
+}
+
+// revealsAnswer reports whether fodder makes its answer too easy to see.
+func revealsAnswer(answer, fodder string, alphabet *Alphabet) bool {
+	answerWords := make(map[string]bool)
+	for _, word := range strings.Fields(answer) {
+		answerWords[alphabet.letterString(word)] = true
+	}
+	for _, word := range strings.Fields(fodder) {
+		letters := alphabet.letterString(word)
+		if len([]rune(letters)) >= min_hidden_letters && answerWords[letters] {
+			return true
+		}
+	}
+	return longestSharedRun(alphabet.letterString(answer), alphabet.letterString(fodder)) >= min_shared_letters
+}
+
+// longestSharedRun is the length of the longest run of letters in both a
+// and b.
+func longestSharedRun(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	longest := 0
+	prev := make([]int, len(br)+1)
+	for i := range ar {
+		cur := make([]int, len(br)+1)
+		for j := range br {
+			if ar[i] == br[j] {
+				cur[j+1] = prev[j] + 1
+				longest = max(longest, cur[j+1])
+			}
+		}
+		prev = cur
+	}
+	return longest
+}
+
+// WriteFodder writes fodder for the answer in input as CSV, one row per
+// phrase with the answer, its enumeration and the fodder's score, for
+// setters to work through. The scorer can be nil to leave scores out, and
+// the alphabet nil for LatinAlphabet.
+func WriteFodder(w io.Writer, input string, fodder []string, scorer *Scorer, alphabet *Alphabet) error {
+	answer, enum, err := ParseClue(input, alphabet)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"answer", "enumeration", "fodder", "score"})
+	for _, phrase := range fodder {
+		score := ""
+		if scorer != nil {
+			score = strconv.FormatFloat(scorer.Score(phrase), 'f', 2, 64)
+		}
+		cw.Write([]string{strings.ToUpper(answer), enum.String(), UnmarkSpaces(phrase), score})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,123 @@
+package anagram
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseEnumeration(t *testing.T) {
+	cases := []struct {
+		text string
+		want Enumeration
+	}{
+		{"(5,4)", Enumeration{5, 4}},
+		{"5-4", Enumeration{5, 4}},
+		{" (3, 2, 7) ", Enumeration{3, 2, 7}},
+		{"(9)", Enumeration{9}},
+	}
+	for _, c := range cases {
+		enum, err := ParseEnumeration(c.text)
+		if err != nil || !slices.Equal(enum, c.want) {
+			t.Errorf("Parsed %q as %v and %v, expected %v", c.text, enum, err, c.want)
+		}
+	}
+	for _, bad := range []string{"()", "(5,x)", "(0)", "(4.5)"} {
+		if _, err := ParseEnumeration(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+	if s := (Enumeration{5, 4}).String(); s != "(5,4)" {
+		t.Errorf("Expected (5,4), got %q", s)
+	}
+}
+
+func TestParseClue(t *testing.T) {
+	cases := []struct {
+		input, answer string
+		enum          Enumeration
+	}{
+		{"karma manager (5,7)", "karma manager", Enumeration{5, 7}},
+		{"karma manager", "karma manager", Enumeration{5, 7}},
+		{"karmamanager (5,7)", "karma manager", Enumeration{5, 7}},
+		{"Jack-in-the-box (4-2-3-3)", "jack in the box", Enumeration{4, 2, 3, 3}},
+	}
+	for _, c := range cases {
+		answer, enum, err := ParseClue(c.input, nil)
+		if err != nil || answer != c.answer || !slices.Equal(enum, c.enum) {
+			t.Errorf("Parsed %q as %q %v and %v, expected %q %v", c.input, answer, enum, err, c.answer, c.enum)
+		}
+	}
+	for _, bad := range []string{"karma manager (5,6)", "(5,7)", "karma (x)"} {
+		if _, _, err := ParseClue(bad, nil); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestRevealsAnswer(t *testing.T) {
+	cases := []struct {
+		answer, fodder string
+		want           bool
+	}{
+		{"scare tears", "trace rates", false},
+		{"scare tears", "tears acres", true}, // the same word
+		{"scare tears", "scarce tea", true},  // "scar" in both
+		{"a test", "a sett", false},          // "a" is too short to count
+		{"a test", "tests a", true},
+	}
+	for _, c := range cases {
+		if got := revealsAnswer(c.answer, c.fodder, LatinAlphabet); got != c.want {
+			t.Errorf("revealsAnswer(%q, %q) = %v, expected %v", c.answer, c.fodder, got, c.want)
+		}
+	}
+}
+
+func TestFindFodder(t *testing.T) {
+	results := collectAll(FindFodder(context.Background(), "scare tears (5,5)", nil, mediumDict, SearchOptions{}))
+	all := collectAll(FindAnagrams("scare tears", nil, mediumDict))
+	for _, result := range results {
+		if !slices.Contains(all, result) {
+			t.Errorf("%q isn't an anagram of the answer", result)
+		}
+		if revealsAnswer("scare tears", result, LatinAlphabet) {
+			t.Errorf("%q gives the answer away", result)
+		}
+	}
+	if !slices.Contains(results, "stare acres") {
+		t.Errorf("Expected stare acres in %v", results)
+	}
+	if len(results) == 0 || len(results) == len(all) {
+		t.Errorf("Expected some but not all of %d anagrams, got %d", len(all), len(results))
+	}
+
+	if results := collectAll(FindFodder(context.Background(), "scare tears (5,4)", nil, mediumDict, SearchOptions{})); len(results) != 0 {
+		t.Errorf("Expected nothing for the wrong enumeration, got %v", results)
+	}
+}
+
+func TestWriteFodder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFodder(&buf, "scaretears (5,5)", []string{"acres stare"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "answer,enumeration,fodder,score\nSCARE TEARS,\"(5,5)\",acres stare,\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	rs := newTestResultSet()
+	rs.SetMode(CrypticMode)
+	rs.FindAnagrams("scare tears")
+	rs.FetchTo(1000)
+	buf.Reset()
+	if err := rs.WriteFodder(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != rs.Count()+1 || rs.Count() == 0 {
+		t.Errorf("Expected a header and %d rows, got %q", rs.Count(), buf.String())
+	}
+	rs.Abort()
+}
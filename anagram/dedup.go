@@ -0,0 +1,53 @@
+package anagram
+
+import "strings"
+
+// Deduper keeps search results unique. Different include phrases can
+// produce the same words in a different order, so results are the same if
+// they have the same words in any order, folded the way the alphabet folds
+// them. Marked phrases stay whole, so a result is the same bunch of
+// dictionary entries CountAnagrams counts, and "new_york city" and
+// "new york city" are different. The input itself is never new.
+type Deduper struct {
+	Ordered bool // results with their words in a different order are different
+
+	alphabet        *Alphabet
+	normalizedInput string
+	seen            map[string]struct{}
+}
+
+// NewDeduper returns a Deduper for the results of input. A nil alphabet
+// means LatinAlphabet.
+func NewDeduper(input string, alphabet *Alphabet) *Deduper {
+	if alphabet == nil {
+		alphabet = LatinAlphabet
+	}
+	return &Deduper{
+		alphabet:        alphabet,
+		normalizedInput: alphabet.Normalize(input),
+		seen:            make(map[string]struct{}),
+	}
+}
+
+// IsNew reports whether result differs from the input and from every result
+// before it, and remembers it.
+func (d *Deduper) IsNew(result string) bool {
+	if d.alphabet.Normalize(result) == d.normalizedInput {
+		return false
+	}
+	key := d.alphabet.normalizeEntries(result)
+	if d.Ordered {
+		key = strings.ToLower(result)
+	}
+
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = struct{}{}
+	return true
+}
+
+// Len is the number of different results seen so far.
+func (d *Deduper) Len() int {
+	return len(d.seen)
+}
@@ -0,0 +1,46 @@
+package anagram
+
+import "testing"
+
+func TestDeduper(t *testing.T) {
+	d := NewDeduper("Star eats", nil)
+	cases := []struct {
+		result string
+		isNew  bool
+	}{
+		{"eats star", false}, // the input in another order
+		{"rats east", true},
+		{"east rats", false},
+		{"East RATS", false},
+		{"rats eäst", false}, // folds to the same letters
+		{"new_york city", true},
+		{"city new york", true}, // the words of a phrase aren't the phrase
+		{"city new_york", false},
+	}
+	for _, c := range cases {
+		if got := d.IsNew(c.result); got != c.isNew {
+			t.Errorf("IsNew(%q) = %v, expected %v", c.result, got, c.isNew)
+		}
+	}
+	if d.Len() != 3 {
+		t.Errorf("Expected 3 results seen, got %d", d.Len())
+	}
+
+	ordered := NewDeduper("star eats", LatinAlphabet)
+	ordered.Ordered = true
+	if !ordered.IsNew("rats east") || !ordered.IsNew("east rats") || ordered.IsNew("East Rats") {
+		t.Error("Expected only the same words in the same order to repeat")
+	}
+	if ordered.IsNew("eats star") {
+		t.Error("The input is never new")
+	}
+
+	spanish, err := NewAlphabet("Spanish", []rune("ñ"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = NewDeduper("año", spanish)
+	if !d.IsNew("ano") {
+		t.Error("Expected ñ to stay distinct from n")
+	}
+}
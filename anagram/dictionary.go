@@ -0,0 +1,409 @@
+package anagram
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// jsonFS holds the dictionary configuration. The word lists themselves are
+// embedded as indexes, see indexFS.
+//
+//go:embed json/main-dicts.json json/added-dicts.json
+var jsonFS embed.FS
+
+type Dictionary struct {
+	Name              string
+	Words             []string
+	Enabled           bool
+	Language          string
+	Alphabet          *Alphabet           // letter model for the words, nil means LatinAlphabet
+	Bonus             float64             // score bonus per word, see Scorer
+	Info              map[string]WordInfo // per-word data, nil for plain word lists
+	annotationLock    sync.Mutex          // guards the annotated fields, which searches on any goroutine can build
+	annotated         annotatedDict       // cached RuneClusters, built once per alphabet
+	annotatedAlphabet *Alphabet
+	annotatedWords    []string          // the Words the annotations were made from
+	signatures        *signatureIndex   // built on first use, see ExactAnagrams
+	loader            *dictionaryLoader // reads Words and Info on first use, nil once they're there
+}
+
+type dictionaryLoader struct {
+	once sync.Once
+	load func(d *Dictionary) error
+	err  error
+}
+
+// Load reads the words of a dictionary from ReadDictionaries, which are
+// only read when they're first needed. The package does this itself; call
+// it before using Words or Info directly.
+func (d *Dictionary) Load() error {
+	if d.loader == nil {
+		return nil
+	}
+	d.loader.once.Do(func() {
+		d.loader.err = d.loader.load(d)
+	})
+	return d.loader.err
+}
+
+// ensureLoaded loads d, giving up if it can't. The indexes are embedded and
+// checked by the tests, so failing to read one is a bug.
+func (d *Dictionary) ensureLoaded() {
+	if err := d.Load(); err != nil {
+		log.Panicln("Can't load dictionary", d.Name, err)
+	}
+}
+
+// alphabet is the dictionary's letter model.
+func (d *Dictionary) alphabet() *Alphabet {
+	if d == nil || d.Alphabet == nil {
+		return LatinAlphabet
+	}
+	return d.Alphabet
+}
+
+// lazyDictionary returns a dictionary that reads its words from the index
+// for file on first use.
+func lazyDictionary(name, file string, alphabet *Alphabet) (*Dictionary, error) {
+	if _, err := fs.Stat(indexFS, indexPath(file)); err != nil {
+		return nil, errors.New("No index for " + file + ", run go generate")
+	}
+
+	d := &Dictionary{Name: name}
+	d.loader = &dictionaryLoader{load: func(d *Dictionary) error {
+		loaded, err := readIndex(name, file, alphabet)
+		if err != nil {
+			return err
+		}
+		d.Words, d.Info = loaded.Words, loaded.Info
+		d.annotated, d.annotatedAlphabet, d.annotatedWords = loaded.annotated, loaded.annotatedAlphabet, loaded.annotatedWords
+		return nil
+	}}
+	return d, nil
+}
+
+// WordInfo is the extra data a dictionary can carry for a word. Frequency
+// is how common the word is, from 0 for the most obscure to 1 for the most
+// common. Tags are parts of speech such as "noun" or "verb".
+type WordInfo struct {
+	Frequency float64
+	Tags      []string `json:"pos"`
+}
+
+// wordEntry is a word in the richer dictionary format:
+//
+//	{"word": "the", "frequency": 0.99, "pos": ["det"]}
+type wordEntry struct {
+	Word string
+	WordInfo
+}
+
+// AlphabetConfig describes an Alphabet in main-dicts.json. Extra lists the
+// letters that are kept distinct; Folds maps single letters to their
+// replacements.
+type AlphabetConfig struct {
+	Name  string
+	Extra string
+	Folds map[string]string
+}
+
+type MainDictionaryConfig struct {
+	Description string
+	File        string
+	Language    string
+	Alphabet    *AlphabetConfig
+}
+
+type AddedDictionaryConfig struct {
+	Description string
+	File        string
+	Enabled     bool
+	Bonus       float64 // added to the score of results using these words
+}
+
+const (
+	main_dicts_file  = "main-dicts.json"
+	added_dicts_file = "added-dicts.json"
+)
+
+// Build turns the config into an Alphabet. A nil config means the default
+// LatinAlphabet.
+func (ac *AlphabetConfig) Build() (*Alphabet, error) {
+	if ac == nil {
+		return LatinAlphabet, nil
+	}
+
+	folds := make(map[rune]string, len(ac.Folds))
+	for letter, replacement := range ac.Folds {
+		runes := []rune(letter)
+		if len(runes) != 1 {
+			return nil, errors.New("Alphabet " + ac.Name + " folds \"" + letter + "\", which isn't a single letter")
+		}
+		folds[unicode.ToLower(runes[0])] = strings.ToLower(replacement)
+	}
+
+	return NewAlphabet(ac.Name, []rune(ac.Extra), folds)
+}
+
+func NewDictionary(name string) *Dictionary {
+	d := &Dictionary{Name: name, Words: make([]string, 50), Enabled: true}
+	return d
+}
+
+// ParseDictionary reads a JSON array of words. Any entry can instead be an
+// object with frequency and part of speech data, see wordEntry.
+func ParseDictionary(name string, jsondata []byte) (*Dictionary, error) {
+	d := &Dictionary{Name: name}
+
+	err := json.Unmarshal(jsondata, &(d.Words))
+	if err == nil {
+		for index, word := range d.Words {
+			d.Words[index] = MarkSpaces(word)
+		}
+		return d, err
+	}
+
+	// Not a plain list of words, so try the richer format.
+	var entries []json.RawMessage
+	if json.Unmarshal(jsondata, &entries) != nil {
+		return nil, err
+	}
+
+	d.Words = make([]string, 0, len(entries))
+	d.Info = make(map[string]WordInfo)
+	for _, raw := range entries {
+		var word string
+		if json.Unmarshal(raw, &word) == nil {
+			d.Words = append(d.Words, MarkSpaces(word))
+			continue
+		}
+
+		var entry wordEntry
+		err = json.Unmarshal(raw, &entry)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Word == "" {
+			return nil, errors.New("Entry without a word in dictionary " + name)
+		}
+		if entry.Frequency < 0 || entry.Frequency > 1 {
+			return nil, errors.New("Frequency of \"" + entry.Word + "\" in dictionary " + name + " isn't between 0 and 1")
+		}
+		word = MarkSpaces(entry.Word)
+		d.Words = append(d.Words, word)
+		d.Info[word] = entry.WordInfo
+	}
+
+	return d, nil
+}
+
+// Frequency returns how common word is, and false if there's no frequency
+// data for it.
+func (d *Dictionary) Frequency(word string) (float64, bool) {
+	d.ensureLoaded()
+	info, ok := d.Info[word]
+	return info.Frequency, ok
+}
+
+// HasTag reports whether word is tagged with the part of speech tag.
+func (d *Dictionary) HasTag(word, tag string) bool {
+	d.ensureLoaded()
+	for _, t := range d.Info[word].Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func ReadConfigs() ([]MainDictionaryConfig, []AddedDictionaryConfig, error) {
+	mainData, err := jsonFS.ReadFile("json/" + main_dicts_file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mainDicts []MainDictionaryConfig
+	err = json.Unmarshal(mainData, &mainDicts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addedData, err := jsonFS.ReadFile("json/" + added_dicts_file)
+	if err != nil {
+		return mainDicts, nil, err
+	}
+
+	var addedDicts []AddedDictionaryConfig
+	err = json.Unmarshal(addedData, &addedDicts)
+	if err != nil {
+		return mainDicts, nil, err
+	}
+	return mainDicts, addedDicts, nil
+}
+
+// ReadDictionaries returns the embedded dictionaries. Their words are read
+// from the index when they're first used, so unused ones cost next to
+// nothing.
+func ReadDictionaries() ([]*Dictionary, []*Dictionary, error) {
+	mainDictConfigs, addedDictConfigs, err := ReadConfigs()
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewDictionaries(mainDictConfigs, addedDictConfigs)
+}
+
+// NewDictionaries is ReadDictionaries for configs that have already been
+// read with ReadConfigs.
+func NewDictionaries(mainDictConfigs []MainDictionaryConfig, addedDictConfigs []AddedDictionaryConfig) ([]*Dictionary, []*Dictionary, error) {
+	var err error
+	var mainDicts []*Dictionary = make([]*Dictionary, len(mainDictConfigs))
+	for i, mdc := range mainDictConfigs {
+		alphabet, err := mdc.Alphabet.Build()
+		if err != nil {
+			return nil, nil, err
+		}
+		mainDicts[i], err = lazyDictionary(mdc.Description, mdc.File, alphabet)
+		if err != nil {
+			return nil, nil, err
+		}
+		mainDicts[i].Language = mdc.Language
+		mainDicts[i].Alphabet = alphabet
+	}
+
+	var addedDicts []*Dictionary = make([]*Dictionary, len(addedDictConfigs))
+	for i, adc := range addedDictConfigs {
+		addedDicts[i], err = lazyDictionary(adc.Description, adc.File, LatinAlphabet)
+		if err != nil {
+			return mainDicts, nil, err
+		}
+		addedDicts[i].Enabled = adc.Enabled
+		addedDicts[i].Bonus = adc.Bonus
+	}
+
+	return mainDicts, addedDicts, nil
+}
+
+// MarkSpaces joins the words of a phrase with underscores so it's treated
+// as a single word everywhere results are split on spaces: in word counts,
+// in the editor and when laying out animations.
+func MarkSpaces(input string) string {
+	return strings.Join(strings.Fields(input), "_")
+}
+
+// UnmarkSpaces turns marked phrases back into plain text for display.
+func UnmarkSpaces(input string) string {
+	return strings.ReplaceAll(input, "_", " ")
+}
+
+// Normalize is Alphabet.Normalize with LatinAlphabet.
+func Normalize(str string) string {
+	return LatinAlphabet.Normalize(str)
+}
+
+// Normalize lower cases and folds the letters of str the way the alphabet
+// does for RuneClusters, drops everything but letters and spaces, and sorts
+// the words, so two phrases with the same words in any order compare equal.
+// Marked phrases are split into their words first.
+func (a *Alphabet) Normalize(str string) string {
+	return a.normalizeEntries(UnmarkSpaces(str))
+}
+
+// normalizeEntries is Normalize without splitting marked phrases, so
+// "new_york city" stays apart from "new york city". Each is a different
+// bunch of dictionary entries.
+func (a *Alphabet) normalizeEntries(str string) string {
+	b := strings.Builder{}
+	for _, c := range strings.Trim(str, " ") {
+		r := rune(c)
+		if unicode.IsSpace(r) || r == '_' {
+			b.WriteRune(r)
+		} else if unicode.IsLetter(r) {
+			b.WriteString(a.FoldString(string(r)))
+		}
+	}
+
+	var words sort.StringSlice = strings.Split(b.String(), " ")
+	words.Sort()
+
+	return strings.Join(words, " ")
+}
+
+// MergeDictionaries combines dicts, leaving out duplicates and the excluded
+// words. The result has the alphabet of the first dictionary, the main one.
+// If the dictionaries are all annotated for that alphabet, so is the
+// result, sharing their RuneClusters.
+func MergeDictionaries(excluded []string, dicts ...*Dictionary) *Dictionary {
+	alphabet := dicts[0].alphabet()
+	annotated := true
+	dictAnnotations := make([]annotatedDict, len(dicts))
+	for i, d := range dicts {
+		d.ensureLoaded()
+		d.annotationLock.Lock()
+		if d.alphabet() != alphabet || !d.annotationsCurrent() {
+			annotated = false
+		}
+		dictAnnotations[i] = d.annotated
+		d.annotationLock.Unlock()
+	}
+
+	var length int = 0
+	names := make([]string, 0, len(dicts))
+	words := make([]string, 0, len(dicts[0].Words))
+	var info map[string]WordInfo
+	var pairs annotatedDict
+	if annotated {
+		pairs = make(annotatedDict, 0, len(dictAnnotations[0]))
+	}
+
+	knownWords := make(map[string]bool)
+	for _, word := range excluded {
+		knownWords[strings.ToLower(word)] = true
+	} // if they're already "known" they won't be added again
+
+	for i, d := range dicts {
+		names = append(names, d.Name)
+		ad := dictAnnotations[i]
+		next := 0 // ad is in the same order as d.Words, less the words it can't spell
+		for _, word := range d.Words {
+			var pair *dictPair
+			if annotated && next < len(ad) && ad[next].Word == word {
+				pair = &ad[next]
+				next += 1
+			}
+			if annotated && pair == nil && alphabet.Covers(word) {
+				annotated = false // Words was edited in place, so the annotations are stale
+			}
+			if !knownWords[strings.ToLower(word)] {
+				knownWords[strings.ToLower(word)] = true
+				length += 1
+				words = append(words, word)
+				if wi, ok := d.Info[word]; ok {
+					if info == nil {
+						info = make(map[string]WordInfo)
+					}
+					info[word] = wi
+				}
+				if pair != nil {
+					pairs = append(pairs, *pair)
+				}
+			}
+		}
+	}
+
+	result := NewDictionary(strings.Join(names, " + "))
+	result.Words = words
+	result.Info = info
+	result.Alphabet = alphabet
+	if annotated {
+		result.setAnnotated(pairs)
+	}
+
+	return result
+}
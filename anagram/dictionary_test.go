@@ -0,0 +1,259 @@
+package anagram
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestDictionary(t *testing.T) {
+	data := []byte(`["Foo","Bar","baz","QUUX"]`)
+
+	dict, err := ParseDictionary("test_dict", data)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if dict == nil {
+		t.Error("got nil dictionary back")
+		return
+	}
+
+	if dict.Name != "test_dict" {
+		t.Error("dictionary name not correct")
+	}
+
+	if len(dict.Words) != 4 {
+		t.Error("Didn't parse proper number of words")
+	}
+
+	if dict.Words[3] != "QUUX" {
+		t.Error("Didn't ready words in order")
+	}
+
+	otherDict, _ := ParseDictionary("other", []byte(`["Fie", "Fy", "Foe", "Foo"]`))
+	combined := MergeDictionaries(make([]string, 0), dict, otherDict)
+
+	if len(combined.Words) == len(dict.Words)+len(otherDict.Words) {
+		t.Error("Merge didn't deduplicate")
+	} else if len(combined.Words) != len(dict.Words)+len(otherDict.Words)-1 {
+		t.Error("Merge didn't deduplicate")
+	}
+
+	if len(combined.Words) == len(dict.Words)+len(otherDict.Words) {
+		t.Error("Merge didn't deduplicate")
+	}
+
+	if combined.Name != "test_dict + other" {
+		t.Error("Merge didn't combine names properly")
+	}
+}
+
+func TestDictionaryParserFailure(t *testing.T) {
+	data := []byte(`{"key": "value"}`)
+
+	dict, err := ParseDictionary("bad_dictionary", data)
+
+	if err == nil {
+		t.Error("Didn't return error on malformed dictionary")
+	}
+
+	if dict != nil {
+		t.Error("Parsing malformed data didn't return nil dict")
+	}
+}
+
+func TestDictionaryWithInfo(t *testing.T) {
+	data := []byte(`["zax", {"word": "the", "frequency": 0.99, "pos": ["det"]}, {"word": "Rat", "frequency": 0.4, "pos": ["noun", "verb"]}]`)
+
+	dict, err := ParseDictionary("rich", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dict.Words) != 3 || dict.Words[0] != "zax" || dict.Words[1] != "the" || dict.Words[2] != "Rat" {
+		t.Errorf("Words not read in order: %v", dict.Words)
+	}
+
+	if f, ok := dict.Frequency("the"); !ok || f != 0.99 {
+		t.Errorf("Wrong frequency for \"the\": %f %v", f, ok)
+	}
+	if _, ok := dict.Frequency("zax"); ok {
+		t.Error("Plain entry shouldn't have frequency data")
+	}
+	if !dict.HasTag("Rat", "verb") || dict.HasTag("Rat", "det") || dict.HasTag("zax", "noun") {
+		t.Error("Tags not read properly")
+	}
+
+	plain, _ := ParseDictionary("plain", []byte(`["tar", "the"]`))
+	combined := MergeDictionaries(nil, plain, dict)
+	if _, ok := combined.Frequency("the"); ok {
+		t.Error("Merge should keep the first dictionary's entry for a word")
+	}
+	if f, _ := combined.Frequency("Rat"); f != 0.4 {
+		t.Error("Merge lost frequency data")
+	}
+
+	for _, bad := range []string{`[{"frequency": 0.5}]`, `[{"word": "x", "frequency": 2}]`, `[{"word": 7}]`} {
+		if d, err := ParseDictionary("bad", []byte(bad)); err == nil || d != nil {
+			t.Errorf("Expected error parsing %s", bad)
+		}
+	}
+}
+
+func TestPhraseEntries(t *testing.T) {
+	dict, err := ParseDictionary("places", []byte(`["New York", " Ho  Chi Minh City "]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dict.Words[0] != "New_York" || dict.Words[1] != "Ho_Chi_Minh_City" {
+		t.Errorf("Phrases not marked: %v", dict.Words)
+	}
+	if UnmarkSpaces(dict.Words[1]) != "Ho Chi Minh City" {
+		t.Errorf("Unmarking gave %q", UnmarkSpaces(dict.Words[1]))
+	}
+	if Normalize("New_York stab") != Normalize("york stab new") {
+		t.Error("Marked phrases should normalize like their words")
+	}
+}
+
+func TestBigDictionary(t *testing.T) {
+	data, rerr := os.ReadFile("json/full-dict.json")
+	if rerr != nil {
+		t.Error(rerr)
+		return
+	}
+
+	dict, perr := ParseDictionary("Full", data)
+	if perr != nil {
+		t.Error(perr)
+		return
+	}
+
+	if len(dict.Words) < 20000 {
+		t.Error("Got truncated dictionary")
+	}
+}
+
+func TestReadConfigs(t *testing.T) {
+	mainConfigs, addedConfigs, err := ReadConfigs()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if mainConfigs == nil || addedConfigs == nil {
+		t.Error("One config list is nil")
+		return
+	}
+
+	if len(mainConfigs) == 0 || len(addedConfigs) == 0 {
+		t.Error("One of the lists is 0 length")
+	}
+
+	for i, mc := range mainConfigs {
+		if mc.File == "" {
+			t.Error(fmt.Sprintf("main config %d has blank filename", i))
+		}
+
+		if mc.Description == "" {
+			t.Error(fmt.Sprintf("main config %d has blank description", i))
+		}
+
+		if mc.Language == "" {
+			t.Error(fmt.Sprintf("main config %d has blank language", i))
+		}
+	}
+
+	for i, ac := range addedConfigs {
+		if ac.File == "" {
+			t.Error(fmt.Sprintf("added config %d has blank filename", i))
+		}
+
+		if ac.Description == "" {
+			t.Error(fmt.Sprintf("added config %d has blank description", i))
+		}
+	}
+}
+
+func TestReadDictionaries(t *testing.T) {
+	mainDicts, addedDicts, err := ReadDictionaries()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(mainDicts) == 0 {
+		t.Error("No main dictionaries")
+	}
+
+	if len(addedDicts) == 0 {
+		t.Error("No added dictionaries")
+	}
+}
+
+func TestMainDictionaryAlphabets(t *testing.T) {
+	mainDicts, _, err := ReadDictionaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	languages := make(map[string]*Dictionary)
+	for _, d := range mainDicts {
+		languages[d.Language] = d
+	}
+
+	for _, lang := range []string{"en", "fr", "es", "de"} {
+		if languages[lang] == nil {
+			t.Fatalf("No main dictionary for language %q", lang)
+		}
+		if err := languages[lang].Load(); err != nil {
+			t.Fatal(err)
+		}
+		if len(languages[lang].Words) == 0 {
+			t.Errorf("Main dictionary for %q is empty", lang)
+		}
+	}
+
+	if languages["en"].Alphabet != LatinAlphabet || languages["fr"].Alphabet != LatinAlphabet {
+		t.Error("English and French should use the Latin alphabet")
+	}
+
+	if languages["es"].Alphabet.Index('ñ') < 0 {
+		t.Error("Spanish alphabet should keep ñ distinct")
+	}
+
+	if languages["de"].Alphabet.Fold('Ä') != "ae" {
+		t.Error("German alphabet should fold ä to ae")
+	}
+
+	for _, lang := range []string{"fr", "es", "de"} {
+		d := languages[lang]
+		if len(NewAnnotatedDict(d)) != len(d.Words) {
+			t.Errorf("%s has words its alphabet can't represent", d.Name)
+		}
+	}
+}
+
+func TestAlphabetConfigBuild(t *testing.T) {
+	var nilConfig *AlphabetConfig
+	if a, err := nilConfig.Build(); err != nil || a != LatinAlphabet {
+		t.Error("Missing alphabet config should give the Latin alphabet")
+	}
+
+	ac := &AlphabetConfig{Name: "test", Extra: "Ñ", Folds: map[string]string{"Ä": "AE"}}
+	a, err := ac.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Index('ñ') != 26 || a.Fold('ä') != "ae" {
+		t.Error("Alphabet config not built properly")
+	}
+
+	bad := &AlphabetConfig{Name: "bad", Folds: map[string]string{"ae": "x"}}
+	if _, err := bad.Build(); err == nil {
+		t.Error("Expected an error for a multi-letter fold")
+	}
+}
@@ -0,0 +1,242 @@
+package anagram
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	default_disk_cache_entries = 200
+	default_disk_cache_bytes   = 64 << 20
+	max_disk_cached_results    = 20000
+	disk_cache_suffix          = ".json"
+)
+
+// DiskCache keeps search results in a directory so a search can pick up
+// where it left off after the app restarts. Each search is one file; once
+// there are more than MaxEntries files or MaxBytes of them, the least
+// recently used are removed.
+type DiskCache struct {
+	Dir        string
+	MaxEntries int
+	MaxBytes   int64
+	lock       sync.Mutex
+}
+
+// NewDiskCache returns a cache in dir, creating the directory if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{Dir: dir, MaxEntries: default_disk_cache_entries, MaxBytes: default_disk_cache_bytes}, nil
+}
+
+// diskCacheKey identifies a search. DictHash covers the words of the
+// combined dictionary, so editing the private dictionary (or an app update
+// changing a word list) makes old entries unreachable; they age out of the
+// cache like any other unused entry.
+type diskCacheKey struct {
+	Input       string      `json:"input"`
+	Dictionary  string      `json:"dictionary"`
+	DictHash    uint64      `json:"dictHash"`
+	Included    []string    `json:"included"`
+	Excluded    []string    `json:"excluded"`
+	Constraints Constraints `json:"constraints"`
+	Order       ResultOrder `json:"order"`
+	Seed        uint64      `json:"seed,omitempty"`
+	Mode        Mode        `json:"mode,omitempty"`
+}
+
+// diskCacheEntry is what's stored for a search: the results so far and how
+// many raw results the generator had produced to get them.
+type diskCacheEntry struct {
+	Key       diskCacheKey `json:"key"`
+	Results   []string     `json:"results"`
+	Generated int          `json:"generated"`
+	Done      bool         `json:"done"`
+}
+
+// dictionaryHash fingerprints the words in a dictionary.
+func dictionaryHash(d *Dictionary) uint64 {
+	d.ensureLoaded()
+	h := fnv.New64a()
+	for _, word := range d.Words {
+		h.Write([]byte(word))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func newDiskCacheKey(state *RSState) diskCacheKey {
+	return diskCacheKey{
+		Input:       state.normalizedInput,
+		Dictionary:  state.combinedDictName,
+		DictHash:    dictionaryHash(state.combinedDict),
+		Included:    orEmpty(state.included),
+		Excluded:    orEmpty(state.excluded),
+		Constraints: state.constraints,
+		Order:       state.order,
+		Seed:        state.seed,
+		Mode:        state.mode,
+	}
+}
+
+// orEmpty makes nil lists empty, so they're written out the same way.
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func (key diskCacheKey) fileName() string {
+	data, _ := json.Marshal(key)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]) + disk_cache_suffix
+}
+
+// sameKey reports whether two keys are the same search, guarding against
+// a file name collision.
+func sameKey(a, b diskCacheKey) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// load returns the stored entry for key, if there is one.
+func (dc *DiskCache) load(key diskCacheKey) (*diskCacheEntry, bool) {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	path := filepath.Join(dc.Dir, key.fileName())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Println("Can't read cached search:", err)
+		}
+		return nil, false
+	}
+
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || !sameKey(entry.Key, key) {
+		log.Println("Discarding unusable cached search", path)
+		os.Remove(path)
+		return nil, false
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now) // keeps it from being trimmed as unused
+	return &entry, true
+}
+
+// store writes entry, replacing any older copy, then trims the cache.
+func (dc *DiskCache) store(entry *diskCacheEntry) error {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dc.Dir, entry.Key.fileName())
+	tmp, err := os.CreateTemp(dc.Dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	dc.trim()
+	return nil
+}
+
+// trim removes the least recently used entries until the cache is within
+// its limits.
+func (dc *DiskCache) trim() {
+	dirEntries, err := os.ReadDir(dc.Dir)
+	if err != nil {
+		log.Println("Can't list search cache:", err)
+		return
+	}
+
+	files := make([]fs.FileInfo, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if !strings.HasSuffix(de.Name(), disk_cache_suffix) {
+			continue
+		}
+		if info, err := de.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[j].ModTime().Before(files[i].ModTime())
+	})
+
+	var total int64
+	for i, info := range files {
+		total += info.Size()
+		if i >= dc.MaxEntries || total > dc.MaxBytes {
+			log.Println("Removing cached search", info.Name())
+			os.Remove(filepath.Join(dc.Dir, info.Name()))
+		}
+	}
+}
+
+// Clear removes every stored search.
+func (dc *DiskCache) Clear() error {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+
+	dirEntries, err := os.ReadDir(dc.Dir)
+	if err != nil {
+		return err
+	}
+	for _, de := range dirEntries {
+		if strings.HasSuffix(de.Name(), disk_cache_suffix) {
+			if err := os.Remove(filepath.Join(dc.Dir, de.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// entryFor captures state for storing. Long searches keep only their first
+// max_disk_cached_results results, along with the generator position right
+// after the last one kept.
+func entryFor(state *RSState) *diskCacheEntry {
+	entry := &diskCacheEntry{
+		Key:       newDiskCacheKey(state),
+		Results:   state.results[:state.resultCount],
+		Generated: state.generated,
+		Done:      state.isDone,
+	}
+	if state.order != RankedOrder && state.resultCount > max_disk_cached_results {
+		entry.Results = entry.Results[:max_disk_cached_results]
+		entry.Generated = state.diskGenerated
+		entry.Done = false
+	}
+	return entry
+}
@@ -0,0 +1,128 @@
+package anagram
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestDiskCache(t *testing.T) *DiskCache {
+	cache, err := NewDiskCache(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+func TestDiskCacheResume(t *testing.T) {
+	cache := newTestDiskCache(t)
+
+	rs := newTestResultSet()
+	rs.SetDiskCache(cache)
+	rs.FindAnagrams("star eats crate")
+	first, ok := rs.GetAt(30)
+	if !ok {
+		t.Fatal("Expected at least 31 results")
+	}
+	rs.Save()
+	seen := rs.Count() // Save stops the search, so there are no more after this
+
+	// A new ResultSet stands in for the app after a restart.
+	restarted := newTestResultSet()
+	restarted.SetDiskCache(cache)
+	restarted.FindAnagrams("star eats crate")
+	if restarted.Count() != seen {
+		t.Errorf("Expected %d results from the cache, got %d", seen, restarted.Count())
+	}
+	if again, _ := restarted.GetAt(30); again != first {
+		t.Errorf("Cached result changed: %q != %q", again, first)
+	}
+
+	// Carrying on must give the rest of the results exactly once.
+	want := 0
+	for r := range FindAnagrams("star eats crate", nil, mediumDict) {
+		if Normalize(r) != Normalize("star eats crate") {
+			want += 1
+		}
+	}
+	got := make(map[string]bool)
+	for i := 0; ; i++ {
+		r, ok := restarted.GetAt(i)
+		if !ok {
+			break
+		}
+		if got[r] {
+			t.Errorf("Repeated result %q after resume", r)
+		}
+		got[r] = true
+	}
+	if len(got) != want {
+		t.Errorf("Resumed search produced %d results, expected %d", len(got), want)
+	}
+	restarted.Abort()
+}
+
+func TestDiskCacheSavesOnSwitch(t *testing.T) {
+	cache := newTestDiskCache(t)
+
+	rs := newTestResultSet()
+	rs.SetDiskCache(cache)
+	rs.FindAnagrams("stare cats")
+	rs.GetAt(5)
+	rs.FindAnagrams("crate tears")
+	rs.Abort()
+
+	files, _ := filepath.Glob(filepath.Join(cache.Dir, "*.json"))
+	if len(files) != 1 {
+		t.Errorf("Expected the first search to be saved when switching, found %d files", len(files))
+	}
+}
+
+func TestDiskCachePrivateDictChange(t *testing.T) {
+	cache := newTestDiskCache(t)
+
+	private := NewDictionary("Private")
+	private.Words = []string{"zzz"}
+	rs := NewResultSet([]*Dictionary{mediumDict}, []*Dictionary{}, private, 0)
+	rs.SetDiskCache(cache)
+	rs.FindAnagrams("stare cats")
+	rs.GetAt(5)
+	rs.Save()
+
+	private.Words = []string{"arcs"}
+	rs.DumpCache()
+	state := NewRSState()
+	state.searchParams = rs.state.searchParams
+	state.normalizedInput = Normalize(state.input)
+	state.combinedDict = rs.CombinedDict()
+	state.seen = state.newDeduper()
+	if rs.loadState(state) {
+		t.Error("Loaded results for a different private dictionary")
+	}
+	rs.Abort()
+}
+
+func TestDiskCacheTrim(t *testing.T) {
+	cache := newTestDiskCache(t)
+	cache.MaxEntries = 2
+
+	rs := newTestResultSet()
+	rs.SetDiskCache(cache)
+	for _, input := range []string{"stare cats", "crate tears", "acres taste", "star eats crate"} {
+		rs.FindAnagrams(input)
+		rs.GetAt(0)
+	}
+	rs.Save()
+
+	files, _ := filepath.Glob(filepath.Join(cache.Dir, "*.json"))
+	if len(files) != 2 {
+		t.Errorf("Expected 2 cached searches, found %d", len(files))
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(cache.Dir); len(entries) != 0 {
+		t.Errorf("Expected an empty cache after Clear, found %d files", len(entries))
+	}
+}
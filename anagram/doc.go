@@ -0,0 +1,32 @@
+// Package anagram is the anagram engine behind Karma Manager. It doesn't
+// depend on any GUI toolkit, so it can be used on its own.
+//
+// Dictionaries are loaded with ReadDictionaries, which reads the word lists
+// embedded in the package as they're needed, or ParseDictionary for your
+// own JSON word lists.
+// Combine them with MergeDictionaries.
+//
+// FindAnagrams and FindAnagramsWithOptions stream results over a channel
+// as the search finds them. SearchOptions controls parallelism,
+// Constraints limits which results are produced and Progress follows how far
+// through its search tree a search has got. FindPartialAnagrams also
+// reports the letters each result leaves unused. ExactAnagrams finds the
+// single words with the same letters as the input without a search,
+// CountAnagrams counts the results without making them and SampleAnagrams
+// streams them in a random order. FindWordplay searches for palindromes,
+// hidden words, spoonerisms, word ladders and cryptic crossword fodder the
+// same way, see Mode. FindAnagramPairs lists the entries of whole
+// dictionaries that are anagrams of each other, and RunBatch finds the
+// anagrams of a whole list of inputs for a report.
+//
+// ResultSet wraps a search for paging through results, caching searches,
+// ranking them with a Scorer and shuffling them.
+//
+//	mains, _, err := anagram.ReadDictionaries()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for result := range anagram.FindAnagrams("Karma Manager", nil, mains[0]) {
+//		fmt.Println(anagram.UnmarkSpaces(result))
+//	}
+package anagram
@@ -0,0 +1,3 @@
+module github.com/pneumaticdeath/KarmaManager/anagram
+
+go 1.23
@@ -0,0 +1,351 @@
+package anagram
+
+import (
+	"bufio"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+//go:generate go run ./internal/mkindex
+
+// indexFS holds a binary index for every word list in json, made by
+// internal/mkindex. Loading one is much quicker than parsing the JSON and
+// counting the letters of every word.
+//
+//go:embed index
+var indexFS embed.FS
+
+const (
+	index_magic   = "KMDX"
+	index_version = 3
+	index_suffix  = ".idx"
+
+	// Flags in the high bits of a word's flag byte. The rest is the number
+	// of repeat bytes.
+	index_has_info  = 0x80
+	index_uncovered = 0x40 // the alphabet can't spell the word, no letters follow
+	index_repeats   = 0x3f
+)
+
+// The index format is:
+//
+//	magic, version byte, alphabet fingerprint (uint64), word count
+//	groups of words of the same length, in annotatedDict order:
+//		length in bytes, word count
+//		for each word:
+//			bytes shared with the word before in the group, the rest of the word
+//			flag byte
+//			unless index_uncovered: a bit for each letter slot in the word,
+//			then repeat bytes of slot<<3 | count, count 1-7, for the letters
+//			it has more than one of
+//			if index_has_info: frequency (float64 bits), tag count, tags
+//
+// Strings are a uvarint length followed by the bytes, and the counts and
+// letter bits are uvarints. Words sorted this way mostly share a good part
+// of the word before, and most letters come once, so it's much smaller than
+// the JSON.
+
+// lessWord is the order searches want words in: longest first, then in
+// reverse alphabetical order.
+func lessWord(a, b string) bool {
+	if len(a) == len(b) {
+		return a > b
+	}
+	return len(a) > len(b)
+}
+
+type indexWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (iw *indexWriter) uvarint(n uint64) {
+	iw.w.Write(iw.buf[:binary.PutUvarint(iw.buf[:], n)])
+}
+
+func (iw *indexWriter) string(s string) {
+	iw.uvarint(uint64(len(s)))
+	iw.w.WriteString(s)
+}
+
+// WriteIndex writes d in the binary index format, counting letters with its
+// alphabet.
+func WriteIndex(w io.Writer, d *Dictionary) error {
+	d.ensureLoaded()
+
+	words := make([]string, len(d.Words))
+	copy(words, d.Words)
+	sort.SliceStable(words, func(i, j int) bool {
+		return lessWord(words[i], words[j])
+	})
+	alphabet := d.alphabet()
+	iw := &indexWriter{w: bufio.NewWriter(w)}
+	iw.w.WriteString(index_magic)
+	iw.w.WriteByte(index_version)
+	binary.LittleEndian.PutUint64(iw.buf[:], alphabet.fingerprint())
+	iw.w.Write(iw.buf[:8])
+	iw.uvarint(uint64(len(words)))
+
+	repeats := make([]byte, 0, 64)
+	for start := 0; start < len(words); {
+		size := len(words[start])
+		end := start + 1
+		for end < len(words) && len(words[end]) == size {
+			end += 1
+		}
+		iw.uvarint(uint64(size))
+		iw.uvarint(uint64(end - start))
+
+		prev := ""
+		for _, word := range words[start:end] {
+			shared := 0
+			for shared < len(prev) && prev[shared] == word[shared] {
+				shared += 1
+			}
+			iw.uvarint(uint64(shared))
+			iw.w.WriteString(word[shared:])
+			prev = word
+
+			var flags byte
+			var bits uint64
+			repeats = repeats[:0]
+			if alphabet.Covers(word) {
+				rc := alphabet.Cluster(word)
+				for slot, count := range rc {
+					if count == 0 {
+						continue
+					}
+					bits |= 1 << slot
+					for count -= 1; count > 0; count -= min(count, 7) {
+						repeats = append(repeats, byte(slot<<3|min(count, 7)))
+					}
+				}
+				if len(repeats) > index_repeats {
+					return errors.New("Too many repeated letters in \"" + word + "\" for the index")
+				}
+			} else {
+				flags |= index_uncovered
+			}
+			info, hasInfo := d.Info[word]
+			if hasInfo {
+				flags |= index_has_info
+			}
+
+			iw.w.WriteByte(flags | byte(len(repeats)))
+			if flags&index_uncovered == 0 {
+				iw.uvarint(bits)
+				iw.w.Write(repeats)
+			}
+			if hasInfo {
+				binary.LittleEndian.PutUint64(iw.buf[:], math.Float64bits(info.Frequency))
+				iw.w.Write(iw.buf[:8])
+				iw.uvarint(uint64(len(info.Tags)))
+				for _, tag := range info.Tags {
+					iw.string(tag)
+				}
+			}
+		}
+		start = end
+	}
+
+	return iw.w.Flush()
+}
+
+var errBadIndex = errors.New("Dictionary index is damaged")
+
+type indexReader struct {
+	data string
+	pos  int
+	err  error
+}
+
+func (ir *indexReader) uvarint64() uint64 {
+	if ir.err != nil {
+		return 0
+	}
+	var n uint64
+	for shift := 0; ir.pos < len(ir.data) && shift < 64; shift += 7 {
+		b := ir.data[ir.pos]
+		ir.pos += 1
+		n |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return n
+		}
+	}
+	ir.err = errBadIndex
+	return 0
+}
+
+// uvarint reads a count or length, which can't be bigger than the index.
+func (ir *indexReader) uvarint() int {
+	n := ir.uvarint64()
+	if n > uint64(len(ir.data)) {
+		ir.err = errBadIndex
+		return 0
+	}
+	return int(n)
+}
+
+func (ir *indexReader) bytes(n int) string {
+	if ir.err != nil || ir.pos+n > len(ir.data) {
+		ir.err = errBadIndex
+		return ""
+	}
+	s := ir.data[ir.pos : ir.pos+n]
+	ir.pos += n
+	return s
+}
+
+func (ir *indexReader) string() string {
+	return ir.bytes(ir.uvarint())
+}
+
+// ParseIndex reads a dictionary written by WriteIndex, giving it alphabet.
+// If it was written with an alphabet with the same letters and folds, the
+// dictionary comes already annotated for it, so the first search doesn't
+// have to count letters.
+func ParseIndex(name string, data []byte, alphabet *Alphabet) (*Dictionary, error) {
+	if alphabet == nil {
+		alphabet = LatinAlphabet
+	}
+
+	ir := &indexReader{data: string(data)}
+	if ir.bytes(len(index_magic)) != index_magic {
+		return nil, errors.New("Dictionary " + name + " isn't an index")
+	}
+	if version := ir.bytes(1); version != string([]byte{index_version}) {
+		return nil, errors.New("Dictionary " + name + " is an index of an unknown version")
+	}
+	fingerprint := ir.bytes(8)
+	count := ir.uvarint()
+	if ir.err != nil {
+		return nil, ir.err
+	}
+	annotate := binary.LittleEndian.Uint64([]byte(fingerprint)) == alphabet.fingerprint()
+
+	// The words are put back together in one buffer, and become slices of
+	// one string rather than a string each.
+	var text strings.Builder
+	text.Grow(len(data))
+	last := make([]byte, 0, 64) // the word before, to build on
+	ends := make([]int, 0, count)
+	infos := make(map[int]*WordInfo)
+	var clusters []RuneCluster
+	var annotated annotatedDict // without the words until they're all read
+	if annotate {
+		clusters = make([]RuneCluster, count)
+		annotated = make(annotatedDict, 0, count)
+	}
+
+	for len(ends) < count && ir.err == nil {
+		size := ir.uvarint()
+		n := ir.uvarint()
+		if n == 0 || n > count-len(ends) {
+			ir.err = errBadIndex
+		}
+		for j := 0; j < n && ir.err == nil; j++ {
+			shared := ir.uvarint()
+			if shared > size || (j == 0 && shared > 0) {
+				ir.err = errBadIndex
+				break
+			}
+			last = append(last[:shared], ir.bytes(size-shared)...)
+			text.Write(last)
+			i := len(ends)
+			ends = append(ends, text.Len())
+
+			flags := ir.bytes(1)
+			if ir.err != nil {
+				break
+			}
+			var slots uint64
+			var repeats string
+			if flags[0]&index_uncovered == 0 {
+				slots = ir.uvarint64()
+				repeats = ir.bytes(int(flags[0] & index_repeats))
+			}
+
+			if flags[0]&index_has_info != 0 {
+				frequency := ir.bytes(8)
+				tags := make([]string, ir.uvarint())
+				for t := range tags {
+					tags[t] = ir.string()
+				}
+				if ir.err != nil {
+					break
+				}
+				infos[i] = &WordInfo{math.Float64frombits(binary.LittleEndian.Uint64([]byte(frequency))), tags}
+			}
+
+			if !annotate || flags[0]&index_uncovered != 0 {
+				continue
+			}
+			if slots >= 1<<maxLetters {
+				ir.err = errBadIndex
+				break
+			}
+			rc := &clusters[i]
+			letters := bits.OnesCount64(slots)
+			for ; slots != 0; slots &= slots - 1 {
+				rc[bits.TrailingZeros64(slots)] = 1
+			}
+			for k := 0; k < len(repeats); k++ {
+				l := repeats[k]
+				rc[l>>3] += int(l & 7)
+				letters += int(l & 7)
+			}
+			annotated = append(annotated, dictPair{"", rc, letters, infos[i]})
+		}
+	}
+	if ir.err != nil {
+		return nil, errors.New("Dictionary " + name + " index is damaged")
+	}
+
+	all := text.String()
+	d := &Dictionary{Name: name, Words: make([]string, count), Alphabet: alphabet}
+	start := 0
+	for i, end := range ends {
+		d.Words[i] = all[start:end]
+		start = end
+	}
+	if len(infos) > 0 {
+		d.Info = make(map[string]WordInfo, len(infos))
+		for i, info := range infos {
+			d.Info[d.Words[i]] = *info
+		}
+	}
+	if annotate {
+		k := 0
+		for i, word := range d.Words {
+			if k < len(annotated) && annotated[k].cluster == &clusters[i] {
+				annotated[k].Word = word
+				k += 1
+			}
+		}
+		d.annotated = annotated
+		d.annotatedAlphabet = alphabet
+		d.annotatedWords = d.Words
+	}
+
+	return d, nil
+}
+
+// readIndex loads the embedded index made from the word list file.
+func readIndex(name, file string, alphabet *Alphabet) (*Dictionary, error) {
+	data, err := indexFS.ReadFile(indexPath(file))
+	if err != nil {
+		return nil, err
+	}
+	return ParseIndex(name, data, alphabet)
+}
+
+// indexPath is where internal/mkindex puts the index for a word list.
+func indexPath(file string) string {
+	return "index/" + strings.TrimSuffix(file, ".json") + index_suffix
+}
@@ -0,0 +1,171 @@
+package anagram
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"testing"
+)
+
+func TestIndexRoundTrip(t *testing.T) {
+	d, err := ParseDictionary("test", []byte(`["cat", "cab", "bat", {"word": "the", "frequency": 0.9, "pos": ["det"]}, "ice cream", "Mississippi", "aaaaaaaaaaaa", "λόγος"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIndex(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ParseIndex("test", buf.Bytes(), LatinAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := append([]string{}, d.Words...)
+	sort.Slice(want, func(i, j int) bool { return lessWord(want[i], want[j]) })
+	if !cmpStringSlices(loaded.Words, want) {
+		t.Errorf("Words %v, expected %v", loaded.Words, want)
+	}
+	if f, ok := loaded.Frequency("the"); !ok || f != 0.9 || !loaded.HasTag("the", "det") {
+		t.Errorf("Lost the word info: %v", loaded.Info)
+	}
+
+	// The annotations must match what NewAnnotatedDict would make.
+	built := NewAnnotatedDict(&Dictionary{Words: loaded.Words, Info: loaded.Info})
+	if loaded.annotatedAlphabet != LatinAlphabet || len(loaded.annotated) != len(built) {
+		t.Fatalf("Expected %d annotated words, got %d", len(built), len(loaded.annotated))
+	}
+	for i, dp := range loaded.annotated {
+		if dp.Word != built[i].Word || *dp.cluster != *built[i].cluster || dp.letters != built[i].letters {
+			t.Errorf("Annotation of %q differs: %v != %v", dp.Word, dp, built[i])
+		}
+	}
+
+	// An index for another alphabet still has the words, just not the
+	// annotations.
+	spanish, _ := NewAlphabet("Spanish", []rune("ñ"), nil)
+	other, err := ParseIndex("test", buf.Bytes(), spanish)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Words) != len(d.Words) || other.annotated != nil {
+		t.Error("Index for another alphabet should load without annotations")
+	}
+
+	// Only the letters and folds matter, not the name.
+	renamed, _ := NewAlphabet("Plain", nil, nil)
+	if same, err := ParseIndex("test", buf.Bytes(), renamed); err != nil || len(same.annotated) != len(built) {
+		t.Errorf("Index should be annotated for an alphabet like its own, got %v", err)
+	}
+	folded, _ := NewAlphabet(LatinAlphabet.Name, nil, map[rune]string{'ß': "s"})
+	if other, err := ParseIndex("test", buf.Bytes(), folded); err != nil || other.annotated != nil {
+		t.Error("Index for an alphabet with other folds but the same name should load without annotations")
+	}
+
+	if _, err := ParseIndex("test", buf.Bytes()[:buf.Len()/2], LatinAlphabet); err == nil {
+		t.Error("Expected an error for a truncated index")
+	}
+	if _, err := ParseIndex("test", []byte(`["cat"]`), LatinAlphabet); err == nil {
+		t.Error("Expected an error for JSON")
+	}
+}
+
+// TestIndexUpToDate checks that the embedded indexes match the word lists.
+func TestIndexUpToDate(t *testing.T) {
+	mainConfigs, addedConfigs, err := ReadConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(file string, alphabet *Alphabet) []byte {
+		data, err := os.ReadFile("json/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := ParseDictionary(file, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Alphabet = alphabet
+		var buf bytes.Buffer
+		if err := WriteIndex(&buf, d); err != nil {
+			t.Fatal(err)
+		}
+		embedded, err := indexFS.ReadFile(indexPath(file))
+		if err != nil || !bytes.Equal(buf.Bytes(), embedded) {
+			t.Errorf("Index for %s is out of date, run go generate", file)
+		}
+		return data
+	}
+
+	for _, mdc := range mainConfigs {
+		alphabet, err := mdc.Alphabet.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := check(mdc.File, alphabet)
+		if index, _ := indexFS.ReadFile(indexPath(mdc.File)); len(index) >= len(data) {
+			t.Errorf("Index for %s is %d bytes, no smaller than its %d byte word list", mdc.File, len(index), len(data))
+		}
+	}
+	for _, adc := range addedConfigs {
+		check(adc.File, LatinAlphabet)
+	}
+}
+
+func TestMergeSharesAnnotations(t *testing.T) {
+	mainDicts, addedDicts, err := ReadDictionaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := MergeDictionaries([]string{"cat"}, mainDicts[0], addedDicts[0])
+	if merged.annotatedAlphabet != LatinAlphabet {
+		t.Fatal("Merging indexed dictionaries should keep their annotations")
+	}
+	ad := merged.annotated
+	if len(ad) == 0 || len(ad) > len(merged.Words) {
+		t.Fatalf("Unexpected number of annotated words: %d of %d", len(ad), len(merged.Words))
+	}
+	for _, dp := range ad[:100] {
+		if !dp.cluster.Equals(NewRuneCluster(dp.Word)) {
+			t.Errorf("Wrong letters for %q", dp.Word)
+		}
+	}
+	for _, dp := range ad {
+		if dp.Word == "cat" {
+			t.Error("Excluded word is still annotated")
+		}
+	}
+}
+
+func BenchmarkLoadJSON(b *testing.B) {
+	data, err := os.ReadFile("json/en_US.json")
+	if err != nil {
+		b.Skip("Could not read the US word list")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d, err := ParseDictionary("US", data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		GetAnnotatedDict(d)
+	}
+}
+
+func BenchmarkLoadIndex(b *testing.B) {
+	data, err := indexFS.ReadFile(indexPath("en_US.json"))
+	if err != nil {
+		b.Skip("Could not read the US index")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d, err := ParseIndex("US", data, LatinAlphabet)
+		if err != nil {
+			b.Fatal(err)
+		}
+		GetAnnotatedDict(d)
+	}
+}
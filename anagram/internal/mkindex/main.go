@@ -0,0 +1,63 @@
+// Command mkindex builds the binary indexes of the dictionaries in json,
+// which is what the anagram package embeds. Run it with go generate in the
+// anagram directory after changing a word list.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+func main() {
+	jsonDir := flag.String("json", "json", "directory holding the word lists")
+	outDir := flag.String("out", "index", "directory to write the indexes to")
+	flag.Parse()
+
+	mainConfigs, addedConfigs, err := anagram.ReadConfigs()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, mdc := range mainConfigs {
+		alphabet, err := mdc.Alphabet.Build()
+		if err != nil {
+			log.Fatal(err)
+		}
+		build(*jsonDir, *outDir, mdc.Description, mdc.File, alphabet)
+	}
+	for _, adc := range addedConfigs {
+		build(*jsonDir, *outDir, adc.Description, adc.File, anagram.LatinAlphabet)
+	}
+}
+
+// build writes the index for one word list, counting letters the way the
+// dictionary will be searched.
+func build(jsonDir, outDir, name, file string, alphabet *anagram.Alphabet) {
+	data, err := os.ReadFile(filepath.Join(jsonDir, file))
+	if err != nil {
+		log.Fatal(err)
+	}
+	d, err := anagram.ParseDictionary(name, data)
+	if err != nil {
+		log.Fatal(file, ": ", err)
+	}
+	d.Alphabet = alphabet
+
+	var buf bytes.Buffer
+	if err := anagram.WriteIndex(&buf, d); err != nil {
+		log.Fatal(file, ": ", err)
+	}
+
+	out := filepath.Join(outDir, strings.TrimSuffix(file, ".json")+".idx")
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %d words, %d bytes\n", out, len(d.Words), buf.Len())
+}
@@ -0,0 +1,169 @@
+package anagram
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnagramPair is two dictionary entries with exactly the same letters, such
+// as "listen" and "silent", and the dictionaries they're from.
+type AnagramPair struct {
+	First            string `json:"first"`
+	FirstDictionary  string `json:"first_dictionary"`
+	Second           string `json:"second"`
+	SecondDictionary string `json:"second_dictionary"`
+	Letters          int    `json:"letters"`
+}
+
+// PairOptions limits which pairs FindAnagramPairs finds.
+type PairOptions struct {
+	MinLetters      int  // the fewest letters an entry can have, 0 for any
+	CrossDictionary bool // only pair entries from different dictionaries
+}
+
+// pairEntry is an entry waiting to be paired with the others that have the
+// same letters.
+type pairEntry struct {
+	dp         *dictPair
+	dictionary string
+	normalized string
+}
+
+// FindAnagramPairs finds every pair of entries in the dictionaries that are
+// anagrams of each other, by grouping them on their letters rather than
+// searching for each one. Entries that only differ in case or spacing
+// aren't paired, and an entry in more than one dictionary is only paired
+// once. Letters are counted with the alphabet of the first dictionary.
+// Pairs come longest first, then alphabetically. If ctx is cancelled it
+// gives up and returns ctx.Err().
+func FindAnagramPairs(ctx context.Context, opts PairOptions, dictionaries ...*Dictionary) ([]AnagramPair, error) {
+	if len(dictionaries) == 0 {
+		return []AnagramPair{}, nil
+	}
+	alphabet := dictionaries[0].alphabet()
+	groups := make(map[uint64][][]pairEntry)
+	seen := make(map[string]bool)
+	checked := 0
+	for _, d := range dictionaries {
+		var ad annotatedDict
+		if d.alphabet() == alphabet {
+			ad = GetAnnotatedDict(d)
+		} else {
+			ad = annotateWith(d, alphabet)
+		}
+		for i := range ad {
+			checked += 1
+			if checked%1000 == 0 && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			dp := &ad[i]
+			if dp.letters == 0 || dp.letters < opts.MinLetters {
+				continue
+			}
+			entry := pairEntry{dp, d.Name, alphabet.Normalize(dp.Word)}
+			if seen[entry.normalized+"\x00"+d.Name] {
+				continue
+			}
+			seen[entry.normalized+"\x00"+d.Name] = true
+
+			// Signatures can collide, so each one has a group for every set
+			// of letters with it.
+			key := dp.cluster.signature()
+			grouped := false
+			for g, group := range groups[key] {
+				if group[0].dp.cluster.Equals(dp.cluster) {
+					groups[key][g] = append(group, entry)
+					grouped = true
+					break
+				}
+			}
+			if !grouped {
+				groups[key] = append(groups[key], []pairEntry{entry})
+			}
+		}
+	}
+
+	pairs := make([]AnagramPair, 0)
+	for _, sameKey := range groups {
+		for _, group := range sameKey {
+			pairs = appendPairs(pairs, group, opts)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if a.Letters != b.Letters {
+			return a.Letters > b.Letters
+		}
+		if !strings.EqualFold(a.First, b.First) {
+			return strings.ToLower(a.First) < strings.ToLower(b.First)
+		}
+		return strings.ToLower(a.Second) < strings.ToLower(b.Second)
+	})
+	return pairs, nil
+}
+
+// appendPairs adds the pairs in a group of entries with the same letters.
+func appendPairs(pairs []AnagramPair, group []pairEntry, opts PairOptions) []AnagramPair {
+	paired := make(map[string]bool)
+	for i, a := range group {
+		for _, b := range group[i+1:] {
+			if a.normalized == b.normalized {
+				continue
+			}
+			if opts.CrossDictionary && a.dictionary == b.dictionary {
+				continue
+			}
+			first, second := a, b
+			if strings.ToLower(second.dp.Word) < strings.ToLower(first.dp.Word) {
+				first, second = second, first
+			}
+			key := first.normalized + "\x00" + second.normalized
+			if paired[key] {
+				continue
+			}
+			paired[key] = true
+			pairs = append(pairs, AnagramPair{
+				First:            first.dp.Word,
+				FirstDictionary:  first.dictionary,
+				Second:           second.dp.Word,
+				SecondDictionary: second.dictionary,
+				Letters:          first.dp.letters,
+			})
+		}
+	}
+	return pairs
+}
+
+// WritePairsJSON writes pairs as a JSON array.
+func WritePairsJSON(w io.Writer, pairs []AnagramPair) error {
+	unmarked := make([]AnagramPair, len(pairs))
+	for i, pair := range pairs {
+		pair.First = UnmarkSpaces(pair.First)
+		pair.Second = UnmarkSpaces(pair.Second)
+		unmarked[i] = pair
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(unmarked)
+}
+
+// WritePairsCSV writes pairs as CSV, with a header row.
+func WritePairsCSV(w io.Writer, pairs []AnagramPair) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"first", "first_dictionary", "second", "second_dictionary", "letters"})
+	for _, pair := range pairs {
+		cw.Write([]string{UnmarkSpaces(pair.First), pair.FirstDictionary, UnmarkSpaces(pair.Second), pair.SecondDictionary, strconv.Itoa(pair.Letters)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
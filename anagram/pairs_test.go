@@ -0,0 +1,86 @@
+package anagram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func pairStrings(pairs []AnagramPair) []string {
+	list := make([]string, len(pairs))
+	for i, pair := range pairs {
+		list[i] = pair.First + "/" + pair.Second
+	}
+	return list
+}
+
+func TestFindAnagramPairs(t *testing.T) {
+	words := &Dictionary{Name: "words", Words: []string{"listen", "silent", "tinsel", "stone", "notes", "cat", "act", "Cat", "dog"}}
+	places := &Dictionary{Name: "places", Words: []string{"Enlist", "Onset", "Listen", "Tac"}}
+
+	pairs, err := FindAnagramPairs(context.Background(), PairOptions{}, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"listen/silent", "listen/tinsel", "silent/tinsel", "notes/stone", "act/cat"}
+	if got := pairStrings(pairs); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if pairs[0].Letters != 6 || pairs[0].FirstDictionary != "words" {
+		t.Errorf("Expected six letters from words, got %+v", pairs[0])
+	}
+
+	pairs, _ = FindAnagramPairs(context.Background(), PairOptions{MinLetters: 5}, words)
+	if got := pairStrings(pairs); slices.Contains(got, "act/cat") || len(got) != 4 {
+		t.Errorf("Expected only the longer pairs, got %v", got)
+	}
+
+	pairs, _ = FindAnagramPairs(context.Background(), PairOptions{CrossDictionary: true}, words, places)
+	for _, pair := range pairs {
+		if pair.FirstDictionary == pair.SecondDictionary {
+			t.Errorf("Expected pairs across dictionaries, got %+v", pair)
+		}
+	}
+	for _, pair := range []string{"Enlist/listen", "Enlist/silent", "Listen/silent", "notes/Onset", "act/Tac"} {
+		if !slices.Contains(pairStrings(pairs), pair) {
+			t.Errorf("Expected %s in %v", pair, pairStrings(pairs))
+		}
+	}
+	if slices.Contains(pairStrings(pairs), "listen/Listen") {
+		t.Errorf("Paired an entry with itself in %v", pairStrings(pairs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FindAnagramPairs(ctx, PairOptions{}, words); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWritePairs(t *testing.T) {
+	pairs := []AnagramPair{{MarkSpaces("los angeles"), "places", MarkSpaces("angel sales"), "words", 10}}
+
+	var buf bytes.Buffer
+	if err := WritePairsCSV(&buf, pairs); err != nil {
+		t.Fatal(err)
+	}
+	want := "first,first_dictionary,second,second_dictionary,letters\nlos angeles,places,angel sales,words,10\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WritePairsJSON(&buf, pairs); err != nil {
+		t.Fatal(err)
+	}
+	var read []AnagramPair
+	if err := json.Unmarshal(buf.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].First != "los angeles" || read[0].Letters != 10 || !strings.Contains(buf.String(), `"second_dictionary": "words"`) {
+		t.Errorf("Unexpected JSON %s", buf.String())
+	}
+}
@@ -0,0 +1,251 @@
+package anagram
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// any_words is the pattern word that stands for any number of other words.
+const any_words = "..."
+
+// ErrCantCountPatterns is returned when asked to count the results of a
+// search with a Pattern.
+var ErrCantCountPatterns = errors.New("Can't count anagrams that match a pattern")
+
+// Pattern describes the words of a phrase, one pattern word for each. In a
+// pattern word, letters stand for themselves, '?' for any one letter and
+// '*' for any number of letters, so "K????" is a five letter word starting
+// with K and "*ing" is any word ending in "ing". A pattern word of "..."
+// allows any number of other words, which go where it is. Case and accents
+// are ignored the way the alphabet ignores them.
+//
+// A phrase matches if each of its words can be given a pattern word of its
+// own that it matches, in any order, and there are no words over unless
+// there's a "...". Matching phrases are put in the pattern's order.
+type Pattern struct {
+	words    [][]rune // the pattern words, folded
+	anyWords int      // where "..." is, or -1 for nowhere
+	alphabet *Alphabet
+}
+
+// ParsePattern reads a Pattern for words in alphabet, nil meaning
+// LatinAlphabet. It returns nil for a blank one.
+func ParsePattern(text string, alphabet *Alphabet) (*Pattern, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if alphabet == nil {
+		alphabet = LatinAlphabet
+	}
+
+	p := &Pattern{anyWords: -1, alphabet: alphabet}
+	for _, field := range fields {
+		if field == any_words {
+			if p.anyWords >= 0 {
+				return nil, errors.New("Only one \"" + any_words + "\" is allowed in a pattern")
+			}
+			p.anyWords = len(p.words)
+			continue
+		}
+		var word []rune
+		for _, r := range field {
+			if r == '?' || r == '*' {
+				word = append(word, r)
+			} else if unicode.IsLetter(r) {
+				word = append(word, []rune(alphabet.Fold(r))...)
+			} else {
+				return nil, errors.New("Pattern word \"" + field + "\" has something other than letters, ? and *")
+			}
+		}
+		p.words = append(p.words, word)
+	}
+	return p, nil
+}
+
+// String is the pattern as ParsePattern reads it.
+func (p *Pattern) String() string {
+	fields := make([]string, 0, len(p.words)+1)
+	for i, word := range p.words {
+		if i == p.anyWords {
+			fields = append(fields, any_words)
+		}
+		fields = append(fields, string(word))
+	}
+	if p.anyWords == len(p.words) {
+		fields = append(fields, any_words)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Matches reports whether word matches any of the pattern words, or could
+// be one of the other words.
+func (p *Pattern) Matches(word string) bool {
+	if p.anyWords >= 0 {
+		return true
+	}
+	letters := []rune(p.alphabet.letterString(word))
+	for _, pw := range p.words {
+		if matchWord(pw, letters) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWord reports whether letters matches the pattern word pw.
+func matchWord(pw, letters []rune) bool {
+	// The usual glob matching, going back to the last '*' on a mismatch.
+	p, l := 0, 0
+	star, starL := -1, 0
+	for l < len(letters) {
+		if p < len(pw) && (pw[p] == '?' || pw[p] == letters[l]) {
+			p, l = p+1, l+1
+		} else if p < len(pw) && pw[p] == '*' {
+			star, starL = p, l
+			p += 1
+		} else if star >= 0 {
+			starL += 1
+			p, l = star+1, starL
+		} else {
+			return false
+		}
+	}
+	for p < len(pw) && pw[p] == '*' {
+		p += 1
+	}
+	return p == len(pw)
+}
+
+// wordCounts is the fewest and most words a matching phrase can have, with
+// zero for no most.
+func (p *Pattern) wordCounts() (int, int) {
+	if p.anyWords >= 0 {
+		return len(p.words), 0
+	}
+	return len(p.words), len(p.words)
+}
+
+// assign finds a pattern word for each of words that can't be one of the
+// other words, returning the word given to each pattern word, -1 for none.
+// With complete set every pattern word must have one. It reports false if
+// there's no way to do it.
+func (p *Pattern) assign(words []string, complete bool) ([]int, bool) {
+	letters := make([][]rune, len(words))
+	for i, word := range words {
+		letters[i] = []rune(p.alphabet.letterString(word))
+	}
+
+	// Augmenting paths, as the phrases are short.
+	given := make([]int, len(p.words)) // word given to each pattern word
+	for i := range given {
+		given[i] = -1
+	}
+	var try func(w int, visited []bool) bool
+	try = func(w int, visited []bool) bool {
+		for i, pw := range p.words {
+			if visited[i] || !matchWord(pw, letters[w]) {
+				continue
+			}
+			visited[i] = true
+			if given[i] < 0 || try(given[i], visited) {
+				given[i] = w
+				return true
+			}
+		}
+		return false
+	}
+
+	matched := 0
+	for w := range words {
+		if try(w, make([]bool, len(p.words))) {
+			matched += 1
+		} else if p.anyWords < 0 {
+			return nil, false // no pattern word left for it
+		}
+	}
+	if complete && matched < len(p.words) {
+		return nil, false
+	}
+	return given, true
+}
+
+// allows reports whether the words of phrase can still be part of a
+// matching phrase.
+func (p *Pattern) allows(phrase string) bool {
+	if p.anyWords >= 0 {
+		return true // more words can fill the pattern and the rest go spare
+	}
+	_, ok := p.assign(strings.Fields(phrase), false)
+	return ok
+}
+
+// arrange puts the words of phrase in the pattern's order, reporting false
+// if it doesn't match.
+func (p *Pattern) arrange(phrase string) (string, bool) {
+	words := strings.Fields(phrase)
+	given, ok := p.assign(words, true)
+	if !ok {
+		return "", false
+	}
+
+	used := make([]bool, len(words))
+	for _, w := range given {
+		used[w] = true
+	}
+	var others []string
+	for w, word := range words {
+		if !used[w] {
+			others = append(others, word)
+		}
+	}
+
+	arranged := make([]string, 0, len(words))
+	for i, w := range given {
+		if i == p.anyWords {
+			arranged = append(arranged, others...)
+		}
+		arranged = append(arranged, words[w])
+	}
+	if p.anyWords == len(p.words) {
+		arranged = append(arranged, others...)
+	}
+	return strings.Join(arranged, " "), true
+}
+
+// preparePattern reads the pattern in the constraints, if any, and narrows
+// the search to match it: words that match no pattern word are dropped and
+// the word counts are limited. It reports false if the pattern is bad or
+// rules out every result.
+func (s *searcher) preparePattern(filtered annotatedDict) (annotatedDict, bool) {
+	c := &s.opts.Constraints
+	pattern, err := ParsePattern(c.Pattern, s.alphabet)
+	if err != nil {
+		log.Println(err)
+		return nil, false
+	}
+	if pattern == nil {
+		return filtered, true
+	}
+	s.pattern = pattern
+
+	fewest, most := pattern.wordCounts()
+	c.MinWords = max(c.MinWords, fewest)
+	if most > 0 && (c.MaxWords <= 0 || most < c.MaxWords) {
+		c.MaxWords = most
+	}
+	if c.MaxWords > 0 && c.MaxWords < c.MinWords {
+		return nil, false
+	}
+
+	matching := make(annotatedDict, 0, len(filtered))
+	for _, dp := range filtered {
+		if pattern.Matches(dp.Word) {
+			matching = append(matching, dp)
+		}
+	}
+	s.words = matching
+	return matching, true
+}
@@ -0,0 +1,133 @@
+package anagram
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	cases := []struct {
+		text, want string
+	}{
+		{"K???? ???????", "k???? ???????"},
+		{"  *ING  ", "*ing"},
+		{"... *ing", "... *ing"},
+		{"c?t ...", "c?t ..."},
+		{"Ñandú", "nandu"},
+	}
+	for _, c := range cases {
+		p, err := ParsePattern(c.text, nil)
+		if err != nil {
+			t.Errorf("Couldn't parse %q: %v", c.text, err)
+		} else if p.String() != c.want {
+			t.Errorf("Parsed %q as %q, expected %q", c.text, p.String(), c.want)
+		}
+	}
+
+	if p, err := ParsePattern("  ", nil); p != nil || err != nil {
+		t.Errorf("Expected nothing for a blank pattern, got %v and %v", p, err)
+	}
+	for _, bad := range []string{"ca7", "c.t", "... a ..."} {
+		if _, err := ParsePattern(bad, nil); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestMatchWord(t *testing.T) {
+	cases := []struct {
+		pattern, word string
+		want          bool
+	}{
+		{"cat", "cat", true},
+		{"cat", "cart", false},
+		{"c?t", "cut", true},
+		{"c?t", "ct", false},
+		{"*ing", "sing", true},
+		{"*ing", "ing", true},
+		{"*ing", "singer", false},
+		{"s*s", "sees", true},
+		{"s*s", "s", false},
+		{"*a*a*", "banana", true},
+		{"*", "", true},
+		{"?*", "", false},
+	}
+	for _, c := range cases {
+		if got := matchWord([]rune(c.pattern), []rune(c.word)); got != c.want {
+			t.Errorf("matchWord(%q, %q) = %v, expected %v", c.pattern, c.word, got, c.want)
+		}
+	}
+}
+
+func TestAnagramsPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		match   func(words []string) bool
+	}{
+		{"????? ???? ????", func(words []string) bool {
+			return len(words) == 3 && len(words[0]) == 5 && len(words[1]) == 4 && len(words[2]) == 4
+		}},
+		{"t* ...", func(words []string) bool {
+			return len(words) >= 1 && strings.HasPrefix(words[0], "t")
+		}},
+		{"... *s *s", func(words []string) bool {
+			n := len(words)
+			return n >= 2 && strings.HasSuffix(words[n-2], "s") && strings.HasSuffix(words[n-1], "s")
+		}},
+		{"a* ? ...", func(words []string) bool {
+			return len(words) >= 2 && strings.HasPrefix(words[0], "a") && len(words[1]) == 1
+		}},
+	}
+
+	all := collectAll(FindAnagrams("star eats crate", nil, mediumDict))
+	for _, c := range cases {
+		opts := SearchOptions{Constraints: Constraints{Pattern: c.pattern}}
+		got := collectAll(FindAnagramsWithOptions(context.Background(), "star eats crate", nil, mediumDict, opts))
+		for _, result := range got {
+			if !c.match(strings.Fields(result)) {
+				t.Errorf("%q doesn't match %q", result, c.pattern)
+			}
+		}
+
+		// Every anagram that matches, in some order, is found.
+		var want []string
+		for _, result := range all {
+			if arranged, ok := mustParsePattern(t, c.pattern).arrange(result); ok {
+				want = append(want, arranged)
+			}
+		}
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("Found %d anagrams matching %q, expected %d", len(got), c.pattern, len(want))
+		}
+		if len(want) == 0 {
+			t.Errorf("Expected some anagrams to match %q", c.pattern)
+		}
+
+		sampled := collectAll(SampleAnagrams(context.Background(), "star eats crate", nil, mediumDict, opts, 1))
+		for _, result := range sampled {
+			if !slices.Contains(want, result) {
+				t.Errorf("Sampled %q, which doesn't match %q", result, c.pattern)
+			}
+		}
+	}
+
+	opts := SearchOptions{Constraints: Constraints{Pattern: "c.t"}}
+	if got := collectAll(FindAnagramsWithOptions(context.Background(), "star eats crate", nil, mediumDict, opts)); len(got) != 0 {
+		t.Errorf("Expected nothing for a bad pattern, got %v", got)
+	}
+	if _, err := CountAnagrams(context.Background(), "star eats crate", nil, mediumDict, SearchOptions{Constraints: Constraints{Pattern: "t* ..."}}); err != ErrCantCountPatterns {
+		t.Errorf("Expected ErrCantCountPatterns, got %v", err)
+	}
+}
+
+func mustParsePattern(t *testing.T, text string) *Pattern {
+	t.Helper()
+	p, err := ParsePattern(text, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
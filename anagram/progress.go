@@ -0,0 +1,100 @@
+package anagram
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// progress_scale is the fixed point value of a whole search.
+	progress_scale = 1 << 52
+	// Branches with at least this share of the search have their share
+	// split between their own branches, so progress moves smoothly through
+	// big ones. Smaller branches count when they finish.
+	min_split_share = 0.01
+	// Splitting a share means counting the words each branch can use. When
+	// that's more subset checks than this, only a sample is counted.
+	max_split_checks = 1 << 18
+	// The number of phrases left to try grows roughly as the number of
+	// usable words to the power of the number of words still to come,
+	// which is about the letters left over this.
+	letters_per_word = 6
+)
+
+// Progress follows how much of its tree a search has explored. Each branch
+// of the search is given a share of the whole, estimated from the number of
+// words it can use and the letters it has left, which is counted when the
+// branch is finished. Put one in SearchOptions and read it from any
+// goroutine while the search runs.
+type Progress struct {
+	done atomic.Uint64
+}
+
+// Fraction is how much of the search is done, from 0 to 1. It only reaches
+// 1 when the search has produced every result.
+func (p *Progress) Fraction() float64 {
+	return min(float64(p.done.Load())/progress_scale, 1)
+}
+
+func (p *Progress) add(share float64) {
+	if p != nil && share > 0 {
+		p.done.Add(uint64(share * progress_scale))
+	}
+}
+
+func (p *Progress) finish() {
+	if p != nil {
+		p.done.Store(progress_scale)
+	}
+}
+
+// splitShare divides share between the first branches words of dict, in
+// proportion to the estimated size of each one's search. It returns nil if
+// share is too small to be worth splitting.
+func (s *searcher) splitShare(share float64, target *RuneCluster, dict annotatedDict, branches int) []float64 {
+	if s.opts.Progress == nil || share < min_split_share || branches == 0 {
+		return nil
+	}
+
+	shares := branchSizes(target, dict, branches)
+	total := 0.0
+	for _, size := range shares {
+		total += size
+	}
+	for i := range shares {
+		shares[i] *= share / total
+	}
+	return shares
+}
+
+// branchSizes estimates the size of the search after each of the first
+// branches words of dict, relative to the biggest, which is 1.
+func branchSizes(target *RuneCluster, dict annotatedDict, branches int) []float64 {
+	// Only every stride'th word is checked if checking them all is too much.
+	checks := branches * (2*len(dict) - branches) / 2
+	stride := max(1, checks/max_split_checks)
+
+	// Sizes can be huge, so they're worked out as logarithms and scaled by
+	// the biggest.
+	logs := make([]float64, branches)
+	biggest := math.Inf(-1)
+	for i := range logs {
+		remaining, err := target.Minus(dict[i].cluster)
+		if err != nil {
+			panic(err) // this shouldn't be possible
+		}
+		usable := 0
+		for j := i; j < len(dict); j += stride {
+			if dict[j].cluster.SubSetOf(remaining) {
+				usable += stride
+			}
+		}
+		logs[i] = float64(remaining.Size()) / letters_per_word * math.Log(float64(usable+1))
+		biggest = max(biggest, logs[i])
+	}
+
+	for i, l := range logs {
+		logs[i] = math.Exp(l - biggest)
+	}
+	return logs
+}
@@ -0,0 +1,69 @@
+package anagram
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	cases := []struct {
+		included []string
+		opts     SearchOptions
+	}{
+		{nil, SearchOptions{Workers: 1}},
+		{nil, SearchOptions{Workers: 4}},
+		{nil, SearchOptions{Workers: 4, Unordered: true}},
+		{[]string{"cat", "rat"}, SearchOptions{Workers: 1}},
+		{nil, SearchOptions{Workers: 1, Constraints: Constraints{MaxWords: 2}}},
+	}
+
+	for _, c := range cases {
+		progress := &Progress{}
+		c.opts.Progress = progress
+		last := 0.0
+		for range FindAnagramsWithOptions(context.Background(), "star eats crate", c.included, mediumDict, c.opts) {
+			f := progress.Fraction()
+			if f < last || f > 1 {
+				t.Errorf("Progress went from %f to %f with %+v", last, f, c.opts)
+			}
+			last = f
+		}
+		if progress.Fraction() != 1 {
+			t.Errorf("Finished search with %+v only got to %f", c.opts, progress.Fraction())
+		}
+	}
+}
+
+func TestProgressCancelled(t *testing.T) {
+	progress := &Progress{}
+	opts := SearchOptions{Workers: 1, Progress: progress}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for range FindAnagramsWithOptions(ctx, "star eats crate", nil, mediumDict, opts) {
+		cancel()
+	}
+	if f := progress.Fraction(); f >= 1 {
+		t.Errorf("Cancelled search reported %f done", f)
+	}
+}
+
+func TestSplitShare(t *testing.T) {
+	dict, target := FilterAnnotatedDict("star eats crate", mediumDict)
+	s := &searcher{opts: SearchOptions{Progress: &Progress{}}}
+	branches := s.countBranches(target, suffixSums(dict, target))
+	shares := s.splitShare(0.5, target, dict, branches)
+	if len(shares) != branches {
+		t.Fatalf("Expected %d shares, got %d", branches, len(shares))
+	}
+	total := 0.0
+	for _, share := range shares {
+		total += share
+	}
+	if total < 0.4999 || total > 0.5001 {
+		t.Errorf("Shares add up to %f, expected 0.5", total)
+	}
+
+	if s.splitShare(min_split_share/2, target, dict, branches) != nil {
+		t.Error("Small shares shouldn't be split")
+	}
+}
@@ -0,0 +1,1042 @@
+package anagram
+
+import (
+	"context"
+	"io"
+	// "fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	max_cached_resultsetstates = 25
+)
+
+const (
+	max_ranked_results      = 1000
+	max_ranked_scanned      = 1000000
+	ranked_publish_interval = 1000
+)
+
+// Estimates aren't made until the search is at least this far through, as
+// before then they swing about too much to be useful.
+const min_estimate_fraction = 0.001
+
+// ResultOrder is the order results are presented in.
+type ResultOrder int
+
+const (
+	// DictionaryOrder streams results in the order the search finds them.
+	DictionaryOrder ResultOrder = iota
+	// RankedOrder scores every result and keeps the best max_ranked_results.
+	RankedOrder
+	// ShuffledOrder streams results in a random order, see SampleAnagrams.
+	// The order is fixed by the shuffle seed. Only anagrams are shuffled.
+	ShuffledOrder
+)
+
+// searchParams are everything that determines a state's results. Cached
+// states are looked up by them.
+type searchParams struct {
+	input            string
+	included         []string
+	excluded         []string
+	combinedDictName string
+	constraints      Constraints
+	order            ResultOrder
+	seed             uint64 // zero unless in ShuffledOrder
+	mode             Mode
+}
+
+func (p searchParams) equals(other searchParams) bool {
+	return p.input == other.input && p.combinedDictName == other.combinedDictName && cmpStringSlices(p.included, other.included) && cmpStringSlices(p.excluded, other.excluded) && p.constraints == other.constraints && p.order == other.order && p.seed == other.seed && p.mode == other.mode
+}
+
+type RSState struct {
+	searchParams
+	normalizedInput string
+	wordCount       map[string]int
+	resultCount     int
+	results         []string
+	topK            *TopK    // the best results so far, in RankedOrder
+	exact           []string // single words with exactly the input's letters
+	totalCount      int      // counted results, -1 until TotalCount has counted them
+	seen            *Deduper // every distinct result so far
+	isDone          bool
+	combinedDict    *Dictionary
+	resultChan      <-chan string
+	cancel          context.CancelFunc
+	ctx             context.Context
+	generated       int // raw results taken from resultChan so far
+	skip            int // raw results to discard after a restarted search
+	diskGenerated   int // generated when the last result that fits on disk arrived
+	savedGenerated  int // generated when last written to the disk cache, -1 if never
+	savedDone       bool
+	progress        *Progress     // how far the running search has got
+	searchTime      time.Duration // spent fetching from the running search
+	fetchStarted    time.Time     // when the current FetchTo started, zero if none is running
+	lastUsed        time.Time
+}
+
+func NewRSState() *RSState {
+	state := &RSState{}
+	state.included = make([]string, 0)
+	state.excluded = make([]string, 0)
+	state.wordCount = make(map[string]int)
+	state.results = make([]string, 0, 25)
+	state.savedGenerated = -1
+	state.totalCount = -1
+	state.lastUsed = time.Now()
+
+	return state
+}
+
+// newDeduper returns an empty Deduper for the state's results. In modes
+// where word order matters it's kept.
+func (state *RSState) newDeduper() *Deduper {
+	d := NewDeduper(state.input, state.combinedDict.alphabet())
+	d.Ordered = state.mode.ordered()
+	return d
+}
+
+// countWords adds the words of result to the state's word counts.
+func (state *RSState) countWords(result string) {
+	for _, word := range strings.Split(result, " ") {
+		if word != "" {
+			state.wordCount[word] += 1
+		}
+	}
+}
+
+// startSearch launches a fresh generator for the state. If the state already
+// has results from a search that was stopped part way, the new generator
+// skips past the ones we've already seen so paging picks up where it left off.
+func (state *RSState) startSearch() {
+	state.stopSearch()
+	state.ctx, state.cancel = context.WithCancel(context.Background())
+	state.skip = state.generated
+	state.progress = &Progress{}
+	state.searchTime = 0
+	opts := DefaultSearchOptions
+	opts.Constraints = state.constraints
+	opts.Progress = state.progress
+	if state.mode != AnagramMode {
+		state.resultChan = FindWordplay(state.ctx, state.mode, state.input, state.included, state.combinedDict, opts)
+	} else if state.order == ShuffledOrder {
+		// The same seed gives the same results in the same order, so
+		// skipping those already seen resumes it.
+		state.resultChan = SampleAnagrams(state.ctx, state.input, state.included, state.combinedDict, opts, state.seed)
+	} else {
+		state.resultChan = FindAnagramsWithOptions(state.ctx, state.input, state.included, state.combinedDict, opts)
+	}
+}
+
+func (state *RSState) stopSearch() {
+	if state.cancel != nil {
+		state.cancel()
+	}
+}
+
+// isStopped reports whether the state's search was cancelled before it
+// produced every result.
+func (state *RSState) isStopped() bool {
+	return !state.isDone && (state.ctx == nil || state.ctx.Err() != nil)
+}
+
+type ResultSet struct {
+	mainDicts            []*Dictionary
+	addedDicts           []*Dictionary
+	privateDict          *Dictionary
+	state                *RSState
+	cached               []*RSState
+	mainDictIndex        int
+	fetchLock            sync.Mutex // held by the one FetchTo pulling results
+	stateLock            sync.Mutex // guards state, fetchTarget and the results of every state
+	fetchTarget          int
+	progressCallback     func(int, int)
+	refreshCallback      func()
+	updateCallback       func()
+	workingStartCallback func()
+	workingStopCallback  func()
+	scorerLock           sync.Mutex
+	scorer               *Scorer
+	scorerDictName       string
+	diskCache            *DiskCache
+	shuffleSeed          uint64
+}
+
+func NewResultSet(mainDicts, addedDicts []*Dictionary, privateDict *Dictionary, mainDictIndex int) *ResultSet {
+	rs := &ResultSet{
+		mainDicts:     mainDicts,
+		addedDicts:    addedDicts,
+		privateDict:   privateDict,
+		state:         NewRSState(),
+		cached:        make([]*RSState, 0),
+		mainDictIndex: mainDictIndex,
+	}
+
+	rs.RebuildDictionaries()
+	rs.FindAnagrams("")
+	return rs
+}
+
+// current returns the state being searched. A state's search parameters
+// never change, so they can be read without the lock; its results can't.
+func (rs *ResultSet) current() *RSState {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	return rs.state
+}
+
+func (rs *ResultSet) SetProgressCallback(cb func(int, int)) {
+	rs.progressCallback = cb
+}
+
+func (rs *ResultSet) SetRefreshCallback(cb func()) {
+	rs.refreshCallback = cb
+}
+
+// SetUpdateCallback is called when results change in place, e.g. when the
+// ranking is updated, so the display can redraw without scrolling.
+func (rs *ResultSet) SetUpdateCallback(cb func()) {
+	rs.updateCallback = cb
+}
+
+func (rs *ResultSet) SetWorkingStartCallback(cb func()) {
+	rs.workingStartCallback = cb
+}
+
+func (rs *ResultSet) SetWorkingStopCallback(cb func()) {
+	rs.workingStopCallback = cb
+}
+
+// SetDiskCache keeps searches in cache as well as in memory, so they can be
+// resumed after a restart. Searches are written out when another search
+// replaces them and when Save is called.
+func (rs *ResultSet) SetDiskCache(cache *DiskCache) {
+	rs.diskCache = cache
+}
+
+// Save writes the current search to the disk cache, if there is one. Call
+// it before exiting.
+func (rs *ResultSet) Save() {
+	rs.Abort()
+	rs.saveState(rs.current())
+}
+
+func (rs *ResultSet) saveState(state *RSState) {
+	if rs.diskCache == nil || state.input == "" || state.combinedDict == nil {
+		return
+	}
+	rs.stateLock.Lock()
+	if state.savedGenerated == state.generated && state.savedDone == state.isDone {
+		rs.stateLock.Unlock()
+		return
+	}
+	entry := entryFor(state)
+	generated, done := state.generated, state.isDone
+	rs.stateLock.Unlock()
+
+	if err := rs.diskCache.store(entry); err != nil {
+		log.Println("Can't save search for", state.input, err)
+		return
+	}
+	rs.stateLock.Lock()
+	state.savedGenerated = generated
+	state.savedDone = done
+	rs.stateLock.Unlock()
+}
+
+// loadState fills a new state in from the disk cache, reporting whether
+// there was anything to load. The state mustn't be in use yet.
+func (rs *ResultSet) loadState(state *RSState) bool {
+	if rs.diskCache == nil || state.input == "" {
+		return false
+	}
+	entry, ok := rs.diskCache.load(newDiskCacheKey(state))
+	if !ok {
+		return false
+	}
+	log.Println("Loaded", len(entry.Results), "cached results for", state.input)
+
+	// In RankedOrder only the best results were kept, so only they are
+	// remembered as seen.
+	var scorer *Scorer
+	if state.order == RankedOrder {
+		state.topK = NewTopK(max_ranked_results)
+		scorer = rs.scorerFor(state)
+	}
+	for _, result := range entry.Results {
+		if !state.seen.IsNew(result) {
+			continue
+		}
+		state.countWords(result)
+		state.results = append(state.results, result)
+		if scorer != nil {
+			state.topK.Add(result, scorer.Score(result))
+		}
+	}
+	state.resultCount = len(state.results)
+	state.generated = entry.Generated
+	state.diskGenerated = entry.Generated
+	state.isDone = entry.Done
+	state.savedGenerated = entry.Generated
+	state.savedDone = entry.Done
+	return true
+}
+
+func (rs *ResultSet) FindAnagrams(input string) {
+	params := rs.current().searchParams
+	params.input = input
+	params.included = make([]string, 0)
+	params.excluded = make([]string, 0)
+	rs.setState(params)
+}
+
+func cmpStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for index, a_element := range a {
+		if a_element != b[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rs *ResultSet) setState(params searchParams) {
+	var state *RSState
+
+	params.seed = 0
+	if params.order == ShuffledOrder {
+		params.seed = rs.shuffleSeed
+	}
+
+	rs.Abort()
+	rs.saveState(rs.current())
+
+	for _, cachedState := range rs.cached {
+		if cachedState.searchParams.equals(params) {
+			rs.stateLock.Lock()
+			log.Println("Using cached RSState", cachedState.input, "with", cachedState.resultCount, "results")
+			cachedState.lastUsed = time.Now()
+			rs.state = cachedState
+			if cachedState.isStopped() {
+				log.Println("Resuming search after", cachedState.generated, "results")
+				cachedState.startSearch()
+			}
+			rs.stateLock.Unlock()
+			if rs.refreshCallback != nil {
+				rs.refreshCallback()
+			}
+			return
+		}
+	}
+
+	log.Println("Building new state for " + params.input)
+
+	state = NewRSState()
+	state.searchParams = params
+	if params.mode == AnagramMode {
+		state.exact = rs.findExact(params.input, params.excluded)
+	}
+	state.combinedDict = rs.GetDict(params.combinedDictName, params.excluded)
+	state.normalizedInput = state.combinedDict.alphabet().Normalize(params.input)
+	state.seen = state.newDeduper()
+
+	for _, ex := range state.excluded {
+		log.Println("constructed exlcusion: ", ex)
+	}
+
+	rs.cached = append(rs.cached, state)
+	rs.trimCache()
+
+	// The state is filled in before anyone else can see it.
+	loaded := rs.loadState(state)
+	rs.stateLock.Lock()
+	rs.state = state
+	if loaded && !state.isDone {
+		state.startSearch()
+	}
+	rs.stateLock.Unlock()
+	if loaded {
+		if rs.refreshCallback != nil {
+			rs.refreshCallback()
+		}
+		return
+	}
+	rs.Regenerate()
+}
+
+func (rs *ResultSet) GetDict(name string, exclusions []string) *Dictionary {
+	log.Println("Building new dict for " + name)
+
+	newDict := rs.CombineDicts(exclusions)
+
+	if newDict == nil {
+		log.Panicln("Got null dictionary from CombineDicts")
+	}
+
+	if newDict.Name != name {
+		log.Panicf("Warning: generated dict name %s != passed dict name %s\n", newDict.Name, name)
+	}
+
+	return newDict
+}
+
+func (rs *ResultSet) trimCache() {
+	sort.Slice(rs.cached, func(i, j int) bool {
+		return rs.cached[j].lastUsed.Before(rs.cached[i].lastUsed)
+	})
+
+	log.Printf("RSState cache is now %d elements\n", len(rs.cached))
+
+	if len(rs.cached) > max_cached_resultsetstates {
+		log.Println("trimming cache")
+		for _, state := range rs.cached[max_cached_resultsetstates:] {
+			state.stopSearch()
+		}
+		rs.cached = rs.cached[:max_cached_resultsetstates]
+	}
+}
+
+func (rs *ResultSet) DumpCache() {
+	log.Println("Dumping state cache")
+	rs.Abort()
+	for _, state := range rs.cached {
+		state.stopSearch()
+	}
+	rs.cached = make([]*RSState, 0)
+	rs.setState(rs.current().searchParams)
+}
+
+// Abort cancels the current state's search and waits for any in-progress
+// FetchTo() to return. The state keeps the results it already has; if it's
+// used again its search is restarted from where it stopped.
+func (rs *ResultSet) Abort() {
+	rs.stateLock.Lock()
+	if !rs.state.isDone && rs.state.cancel != nil {
+		log.Println("Aborting search for", rs.state.input)
+		rs.state.stopSearch()
+	}
+	rs.stateLock.Unlock()
+	rs.fetchLock.Lock()
+	rs.fetchLock.Unlock()
+}
+
+func (rs *ResultSet) RebuildDictionaries() {
+	rs.Abort()
+	params := rs.current().searchParams
+	params.combinedDictName = rs.MakeCombinedDictName()
+	rs.setState(params)
+}
+
+func (rs *ResultSet) MakeCombinedDictName() string {
+	names := make([]string, 0, len(rs.addedDicts)+2)
+
+	names = append(names, rs.mainDicts[rs.mainDictIndex].Name)
+	for _, ad := range rs.addedDicts {
+		if ad.Enabled {
+			names = append(names, ad.Name)
+		}
+	}
+	if rs.privateDict.Enabled {
+		names = append(names, "Private")
+	}
+
+	return strings.Join(names, " + ")
+}
+
+func (rs *ResultSet) Regenerate() {
+	rs.stateLock.Lock()
+	state := rs.state
+	state.resultCount = 0
+	rs.fetchTarget = 0
+	state.wordCount = make(map[string]int)
+	state.results = make([]string, 0, 110)
+	state.seen = state.newDeduper()
+	state.isDone = false
+	state.generated = 0
+	if state.order == RankedOrder {
+		state.topK = NewTopK(max_ranked_results)
+	}
+	state.startSearch()
+	rs.stateLock.Unlock()
+	go func() {
+		rs.FetchTo(25)
+		if rs.refreshCallback != nil {
+			rs.refreshCallback()
+		}
+	}()
+}
+
+// selectedDicts returns the main dictionary and the enabled added and
+// private ones.
+func (rs *ResultSet) selectedDicts() []*Dictionary {
+	dicts := make([]*Dictionary, 0, len(rs.addedDicts)+2)
+	dicts = append(dicts, rs.mainDicts[rs.mainDictIndex])
+	for _, d := range rs.addedDicts {
+		if d.Enabled {
+			dicts = append(dicts, d)
+		}
+	}
+	if rs.privateDict.Enabled {
+		dicts = append(dicts, rs.privateDict)
+	}
+	return dicts
+}
+
+func (rs *ResultSet) CombineDicts(excluded []string) *Dictionary {
+	return MergeDictionaries(excluded, rs.selectedDicts()...)
+}
+
+// findExact looks up the single words with exactly the letters of input in
+// each selected dictionary. Doing it here, before the dictionaries are
+// combined, also annotates them, so MergeDictionaries can reuse their
+// RuneClusters.
+func (rs *ResultSet) findExact(input string, excluded []string) []string {
+	exact := make([]string, 0)
+	if strings.TrimSpace(input) == "" {
+		return exact
+	}
+
+	known := make(map[string]bool)
+	for _, word := range excluded {
+		known[strings.ToLower(word)] = true
+	}
+	for _, d := range rs.selectedDicts() {
+		for _, word := range ExactAnagrams(input, d) {
+			if !known[strings.ToLower(word)] {
+				known[strings.ToLower(word)] = true
+				exact = append(exact, word)
+			}
+		}
+	}
+	sort.Slice(exact, func(i, j int) bool {
+		return strings.ToLower(exact[i]) < strings.ToLower(exact[j])
+	})
+	return exact
+}
+
+// ExactAnagrams returns the words, from the selected dictionaries less the
+// exclusions, that use exactly the letters of the input, e.g. "silent" and
+// "tinsel" for "listen". They're found as soon as the input is set, before
+// any results.
+func (rs *ResultSet) ExactAnagrams() []string {
+	return rs.current().exact
+}
+
+// Input returns the phrase being searched.
+func (rs *ResultSet) Input() string {
+	return rs.current().input
+}
+
+func (rs *ResultSet) Inclusions() []string {
+	return rs.current().included
+}
+
+func (rs *ResultSet) Exclusions() []string {
+	return rs.current().excluded
+}
+
+// CombinedDict returns the dictionary being searched, made of the selected
+// dictionaries less the exclusions.
+func (rs *ResultSet) CombinedDict() *Dictionary {
+	return rs.current().combinedDict
+}
+
+func (rs *ResultSet) CombinedDictName() string {
+	return rs.current().combinedDictName
+}
+
+// Alphabet returns the alphabet of the selected main dictionary, which the
+// search counts letters with.
+func (rs *ResultSet) Alphabet() *Alphabet {
+	return rs.mainDicts[rs.mainDictIndex].alphabet()
+}
+
+func (rs *ResultSet) SetMainIndex(index int) {
+	rs.mainDictIndex = index
+	rs.RebuildDictionaries()
+}
+
+// Scorer returns the scorer for the current combination of dictionaries,
+// giving a bonus to words from added dictionaries that have one configured.
+func (rs *ResultSet) Scorer() *Scorer {
+	return rs.scorerFor(rs.current())
+}
+
+// scorerFor returns the scorer for state's combination of dictionaries.
+func (rs *ResultSet) scorerFor(state *RSState) *Scorer {
+	rs.scorerLock.Lock()
+	defer rs.scorerLock.Unlock()
+	name := state.combinedDictName
+	if rs.scorer == nil || rs.scorerDictName != name {
+		scorer := NewScorer()
+		scorer.Frequency = DictionaryFrequency(state.combinedDict)
+		for _, d := range rs.addedDicts {
+			if d.Enabled && d.Bonus != 0 {
+				scorer.AddBonus(d, d.Bonus)
+			}
+		}
+		rs.scorer = scorer
+		rs.scorerDictName = name
+	}
+	return rs.scorer
+}
+
+// publishRanked replaces the state's results with the current ranking.
+func (rs *ResultSet) publishRanked(state *RSState) {
+	rs.stateLock.Lock()
+	sorted := state.topK.Sorted()
+	results := make([]string, len(sorted))
+	for i, sr := range sorted {
+		results[i] = sr.Result
+	}
+	state.results = results
+	state.resultCount = len(results)
+	generated := state.generated
+	rs.stateLock.Unlock()
+
+	if rs.progressCallback != nil {
+		rs.progressCallback(generated, max_ranked_scanned)
+	}
+	if rs.updateCallback != nil {
+		rs.updateCallback()
+	}
+}
+
+// FetchTo pulls results from the search until there are at least target of
+// them. In RankedOrder every result has to be seen before the best ones are
+// known, so it keeps going until the search is done or aborted, publishing
+// the ranking as it goes. Only one FetchTo pulls results at a time; asking
+// for more while one is running raises its target and waits for it.
+func (rs *ResultSet) FetchTo(target int) {
+	rs.stateLock.Lock()
+	if rs.state.isDone || rs.state.isStopped() {
+		rs.stateLock.Unlock()
+		return
+	}
+	if rs.fetchTarget < target {
+		log.Printf("New fetch target %d\n", target)
+		rs.fetchTarget = target
+	}
+	rs.stateLock.Unlock()
+
+	// If another FetchTo is running, it's already heading for the new target,
+	// so this one just waits for it to get there.
+	rs.fetchLock.Lock()
+	log.Println("Acquired lock")
+	defer func() {
+		rs.fetchLock.Unlock()
+		log.Printf("Released lock at %d\n", rs.Count())
+	}()
+
+	// The state may have been replaced, or the target reached, while we
+	// waited for the lock.
+	rs.stateLock.Lock()
+	state := rs.state
+	if state.isDone || state.isStopped() || (state.order != RankedOrder && state.resultCount >= rs.fetchTarget) {
+		rs.stateLock.Unlock()
+		return
+	}
+	state.fetchStarted = time.Now()
+	resultChan := state.resultChan
+	count, fetchTarget := state.resultCount, rs.fetchTarget
+	rs.stateLock.Unlock()
+	defer func() {
+		rs.stateLock.Lock()
+		state.searchTime += time.Since(state.fetchStarted)
+		state.fetchStarted = time.Time{}
+		rs.stateLock.Unlock()
+	}()
+
+	if rs.workingStartCallback != nil {
+		rs.workingStartCallback()
+	}
+	if rs.progressCallback != nil {
+		rs.progressCallback(count, fetchTarget)
+	}
+
+	ranked := state.order == RankedOrder
+	var scorer *Scorer
+	if ranked {
+		scorer = rs.scorerFor(state)
+	}
+
+	for {
+		rs.stateLock.Lock()
+		more := !state.isDone && (ranked || state.resultCount < rs.fetchTarget)
+		rs.stateLock.Unlock()
+		if !more {
+			break
+		}
+
+		// Waiting for the search mustn't hold up anyone reading the results.
+		next, ok := <-resultChan
+
+		rs.stateLock.Lock()
+		publish, report := false, false
+		if ok {
+			if state.skip > 0 {
+				state.skip -= 1
+				rs.stateLock.Unlock()
+				continue
+			}
+			state.generated += 1
+			// log.Println("Got anagram ", next)
+			if state.seen.IsNew(next) {
+				state.countWords(next)
+				if ranked {
+					state.topK.Add(next, scorer.Score(next))
+				} else {
+					state.results = append(state.results, next)
+					state.resultCount += 1
+					if state.resultCount == max_disk_cached_results {
+						state.diskGenerated = state.generated
+					}
+					report = state.resultCount%100 == 0
+				}
+			}
+			publish = ranked && state.generated%ranked_publish_interval == 0
+			if ranked && state.generated >= max_ranked_scanned {
+				log.Printf("Ranked the first %d results, stopping search\n", state.generated)
+				state.stopSearch()
+				state.isDone = true
+			}
+		} else if state.ctx.Err() != nil {
+			log.Println("FetchTo() aborted")
+			rs.stateLock.Unlock()
+			break
+		} else {
+			rs.fetchTarget = state.resultCount
+			state.isDone = true
+		}
+		count, fetchTarget = state.resultCount, rs.fetchTarget
+		rs.stateLock.Unlock()
+
+		if publish {
+			rs.publishRanked(state)
+		}
+		if report && rs.progressCallback != nil {
+			rs.progressCallback(count, fetchTarget)
+		}
+	}
+	if ranked {
+		rs.publishRanked(state)
+		rs.stateLock.Lock()
+		rs.fetchTarget = state.resultCount
+		rs.stateLock.Unlock()
+	}
+	if rs.progressCallback != nil {
+		rs.stateLock.Lock()
+		count, fetchTarget = rs.state.resultCount, rs.fetchTarget
+		rs.stateLock.Unlock()
+		rs.progressCallback(count, fetchTarget)
+	}
+
+	if rs.workingStopCallback != nil {
+		rs.workingStopCallback()
+	}
+}
+
+func (rs *ResultSet) IsDone() bool {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	return rs.state.isDone
+}
+
+// TotalCount counts the current search's results without fetching them,
+// see CountAnagrams. The input itself is never a result, so it isn't
+// counted, but results made from more than one included phrase are counted
+// once for each. The count is remembered, so asking again is free. If ctx is
+// cancelled it gives up and returns ctx.Err().
+func (rs *ResultSet) TotalCount(ctx context.Context) (int, error) {
+	rs.stateLock.Lock()
+	state := rs.state
+	totalCount, isDone, resultCount := state.totalCount, state.isDone, state.resultCount
+	rs.stateLock.Unlock()
+	if totalCount >= 0 {
+		return totalCount, nil
+	}
+	if isDone && state.order == DictionaryOrder {
+		return resultCount, nil
+	}
+	if state.mode != AnagramMode {
+		return 0, ErrOnlyAnagrams
+	}
+
+	opts := SearchOptions{Constraints: state.constraints}
+	count, err := CountAnagrams(ctx, state.input, state.included, state.combinedDict, opts)
+	if err != nil {
+		return 0, err
+	}
+	count -= inputResults(ctx, state, opts)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	rs.stateLock.Lock()
+	state.totalCount = count
+	rs.stateLock.Unlock()
+	return count, nil
+}
+
+// inputResults is how many of the state's search results are just the
+// input again. Only dictionary words made of the input's words can be in
+// them, so it searches with those alone.
+func inputResults(ctx context.Context, state *RSState, opts SearchOptions) int {
+	inputWords := make(map[string]bool)
+	for _, word := range strings.Fields(state.normalizedInput) {
+		inputWords[word] = true
+	}
+
+	alphabet := state.combinedDict.alphabet()
+	own := &Dictionary{Name: "input", Alphabet: alphabet}
+	for _, word := range state.combinedDict.Words {
+		words := strings.Fields(alphabet.Normalize(word))
+		ok := len(words) > 0
+		for _, w := range words {
+			ok = ok && inputWords[w]
+		}
+		if !ok {
+			continue
+		}
+		own.Words = append(own.Words, word)
+		if info, ok := state.combinedDict.Info[word]; ok {
+			if own.Info == nil {
+				own.Info = make(map[string]WordInfo)
+			}
+			own.Info[word] = info
+		}
+	}
+
+	n := 0
+	for result := range FindAnagramsWithOptions(ctx, state.input, state.included, own, opts) {
+		if alphabet.Normalize(result) == state.normalizedInput {
+			n += 1
+		}
+	}
+	return n
+}
+
+// SearchProgress is how far through the current search is, and what's
+// expected by the time it finishes.
+type SearchProgress struct {
+	Fraction       float64       // of the search done, from 0 to 1
+	EstimatedTotal int           // estimated number of results, -1 if not known yet
+	ETA            time.Duration // estimated searching time left, -1 if not known yet
+}
+
+// Progress reports how far through the current search is. The estimates
+// assume the rest of the search finds results at the rate it has so far.
+// Time only counts while results are being fetched, so the ETA is how much
+// longer fetching would take to finish the search. There are no estimates
+// while a resumed search is skipping the results it had before.
+func (rs *ResultSet) Progress() SearchProgress {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	state := rs.state
+	if state.isDone {
+		return SearchProgress{1, state.resultCount, 0}
+	}
+
+	p := SearchProgress{0, -1, -1}
+	if state.progress == nil {
+		return p
+	}
+	p.Fraction = state.progress.Fraction()
+	if p.Fraction < min_estimate_fraction {
+		return p
+	}
+	// A resumed search starts again from the beginning, skipping the results
+	// it already found, so they're only a guide to the rest once it's caught
+	// up with them.
+	if state.skip > 0 {
+		return p
+	}
+
+	// seen holds every distinct result, even in RankedOrder where only the
+	// best are kept.
+	p.EstimatedTotal = int(float64(state.seen.Len()) / p.Fraction)
+	elapsed := state.searchTime
+	if !state.fetchStarted.IsZero() {
+		elapsed += time.Since(state.fetchStarted)
+	}
+	if elapsed > 0 {
+		p.ETA = time.Duration(float64(elapsed) * (1 - p.Fraction) / p.Fraction)
+	}
+	return p
+}
+
+func (rs *ResultSet) Count() int {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	return rs.state.resultCount
+}
+
+func (rs *ResultSet) IsEmpty() bool {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	return rs.state.resultCount == 0 && rs.state.isDone
+}
+
+func (rs *ResultSet) GetAt(index int) (string, bool) {
+	// log.Printf("Getting item at %d\n", index)
+	rs.stateLock.Lock()
+	state := rs.state
+	defer rs.stateLock.Unlock()
+	if index > state.resultCount-10 {
+		go func() {
+			rs.FetchTo(index + 10)
+		}()
+		for !state.isDone && !state.isStopped() && index >= state.resultCount {
+			// log.Println("GetAt waiting...")
+			rs.stateLock.Unlock()
+			time.Sleep(time.Millisecond)
+			rs.stateLock.Lock()
+		}
+	}
+
+	if index < state.resultCount {
+		return state.results[index], true
+	} else {
+		return "", false
+	}
+}
+
+func (rs *ResultSet) SetInclusions(phrases []string) {
+	params := rs.current().searchParams
+	params.included = phrases
+	rs.setState(params)
+}
+
+func (rs *ResultSet) SetExclusions(words []string) {
+	params := rs.current().searchParams
+	params.excluded = words
+	rs.setState(params)
+}
+
+// SetConstraints limits the number and length of words in the results.
+// Constraints carry over when the input changes.
+func (rs *ResultSet) SetConstraints(c Constraints) {
+	params := rs.current().searchParams
+	params.constraints = c
+	rs.setState(params)
+}
+
+func (rs *ResultSet) Constraints() Constraints {
+	return rs.current().constraints
+}
+
+// LeftoverAt returns the letters of the input that the result at index
+// doesn't use. It's empty unless the constraints allow partial anagrams.
+func (rs *ResultSet) LeftoverAt(index int) string {
+	result, ok := rs.GetAt(index)
+	state := rs.current()
+	if !ok || state.mode != AnagramMode {
+		return ""
+	}
+	alphabet := rs.Alphabet()
+	leftover := alphabet.Leftover(state.input, result)
+	if leftover == nil {
+		return ""
+	}
+	return leftover.Spell(alphabet)
+}
+
+// SetOrder switches between streaming results as they're found, ranking
+// them best first and shuffling them.
+func (rs *ResultSet) SetOrder(order ResultOrder) {
+	params := rs.current().searchParams
+	params.order = order
+	rs.setState(params)
+}
+
+func (rs *ResultSet) Order() ResultOrder {
+	return rs.current().order
+}
+
+// SetMode switches the kind of wordplay searched for. The mode carries over
+// when the input changes.
+func (rs *ResultSet) SetMode(mode Mode) {
+	params := rs.current().searchParams
+	params.mode = mode
+	rs.setState(params)
+}
+
+func (rs *ResultSet) Mode() Mode {
+	return rs.current().mode
+}
+
+// SetShuffleSeed picks which random order ShuffledOrder uses. Searches
+// with the same seed come out the same.
+func (rs *ResultSet) SetShuffleSeed(seed uint64) {
+	rs.shuffleSeed = seed
+	if state := rs.current(); state.order == ShuffledOrder {
+		rs.setState(state.searchParams)
+	}
+}
+
+func (rs *ResultSet) ShuffleSeed() uint64 {
+	return rs.shuffleSeed
+}
+
+// WriteFodder writes the results fetched so far as fodder for the input, in
+// their current order and with their scores, see WriteFodder.
+func (rs *ResultSet) WriteFodder(w io.Writer) error {
+	rs.stateLock.Lock()
+	state := rs.state
+	results := state.results[:state.resultCount]
+	rs.stateLock.Unlock()
+	return WriteFodder(w, state.input, results, rs.scorerFor(state), rs.Alphabet())
+}
+
+type WordCount struct {
+	Word   string
+	Count  int
+	metric int
+}
+
+// NewWordCount ranks a word that appeared count times. Longer words rank
+// higher; phrases count their letters, not the marks between their words.
+func NewWordCount(word string, count int) WordCount {
+	n := NewRuneCluster(word).Size()
+	return WordCount{word, count, n * n * count}
+}
+
+type Counts []WordCount
+
+func (c Counts) Len() int {
+	return len(c)
+}
+
+func (c Counts) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func (c Counts) Less(i, j int) bool {
+	return c[j].metric < c[i].metric
+}
+
+func (rs *ResultSet) TopNWords(n int) Counts {
+	rs.stateLock.Lock()
+	defer rs.stateLock.Unlock()
+	words := make(Counts, 0, len(rs.state.wordCount))
+	for w, c := range rs.state.wordCount {
+		words = append(words, NewWordCount(w, c))
+	}
+
+	sort.Sort(words)
+
+	if len(words) > n {
+		return words[:n]
+	} else {
+		return words
+	}
+}
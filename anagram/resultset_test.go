@@ -0,0 +1,471 @@
+package anagram
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestResultSet builds a ResultSet over mediumDict with no added or
+// private dictionaries.
+func newTestResultSet() *ResultSet {
+	private := NewDictionary("Private")
+	private.Enabled = false
+	return NewResultSet([]*Dictionary{mediumDict}, []*Dictionary{}, private, 0)
+}
+
+// waitForGoroutines polls until the goroutine count drops to at most n,
+// returning the last count seen.
+func waitForGoroutines(n int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	count := runtime.NumGoroutine()
+	for count > n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		count = runtime.NumGoroutine()
+	}
+	return count
+}
+
+func TestFindAnagramsContextCancel(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := FindAnagramsContext(ctx, "star eats crate", nil, mediumDict)
+	<-ch // the generator is now running and will soon block on a full channel
+	cancel()
+
+	// The channel must be closed promptly once the context is cancelled.
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				if count := waitForGoroutines(baseline, 2*time.Second); count > baseline {
+					t.Errorf("Generator goroutine still running: %d > %d", count, baseline)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Channel not closed after cancel")
+		}
+	}
+}
+
+func TestResultSetNoGoroutineLeak(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	rs := newTestResultSet()
+	inputs := []string{"star eats crate", "stare cats", "crate tears", "acres taste"}
+	for i := 0; i < 50; i++ {
+		rs.FindAnagrams(fmt.Sprintf("%s %s", inputs[i%len(inputs)], inputs[(i+1)%len(inputs)][:i%5]))
+	}
+	rs.Abort()
+
+	if count := waitForGoroutines(baseline, 2*time.Second); count > baseline {
+		t.Errorf("Leaked goroutines after rapid input changes: %d > %d", count, baseline)
+	}
+}
+
+func TestResultSetResumeAfterAbort(t *testing.T) {
+	rs := newTestResultSet()
+
+	rs.FindAnagrams("star eats crate")
+	first, ok := rs.GetAt(30)
+	if !ok {
+		t.Fatal("Expected at least 31 results")
+	}
+	seen := rs.Count()
+
+	rs.FindAnagrams("cats")
+	rs.FindAnagrams("star eats crate")
+	if rs.Count() != seen {
+		t.Errorf("Cached state lost results: %d != %d", rs.Count(), seen)
+	}
+	if again, _ := rs.GetAt(30); again != first {
+		t.Errorf("Cached result changed: %q != %q", again, first)
+	}
+
+	// Fetching past what we had must continue the sequence without repeats.
+	want := 0
+	for r := range FindAnagrams("star eats crate", nil, mediumDict) {
+		if Normalize(r) != Normalize("star eats crate") {
+			want += 1
+		}
+	}
+	got := make(map[string]bool)
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		if got[r] {
+			t.Errorf("Repeated result %q after resume", r)
+		}
+		got[r] = true
+	}
+	if len(got) != want {
+		t.Errorf("Resumed search produced %d results, expected %d", len(got), want)
+	}
+	rs.Abort()
+}
+
+func TestResultSetSwitchesAlphabet(t *testing.T) {
+	spanish, _ := NewAlphabet("Spanish", []rune{'ñ'}, nil)
+	spanishDict := &Dictionary{Name: "spanish", Words: []string{"año", "ano", "o", "a", "ñ", "n"}, Alphabet: spanish}
+	private := NewDictionary("Private")
+	private.Enabled = false
+
+	rs := NewResultSet([]*Dictionary{mediumDict, spanishDict}, []*Dictionary{}, private, 0)
+
+	rs.SetMainIndex(1)
+	if rs.Alphabet() != spanish || rs.CombinedDict().Alphabet != spanish {
+		t.Fatal("Selecting the Spanish dictionary didn't switch the alphabet")
+	}
+
+	rs.FindAnagrams("año")
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		if spanish.Cluster(r)[spanish.Index('ñ')] == 0 {
+			t.Errorf("Result %q should contain ñ", r)
+		}
+	}
+
+	rs.SetMainIndex(0)
+	if rs.Alphabet() != LatinAlphabet {
+		t.Error("Selecting the English dictionary didn't restore the Latin alphabet")
+	}
+	rs.Abort()
+}
+
+func TestResultSetConstraints(t *testing.T) {
+	rs := newTestResultSet()
+
+	rs.FindAnagrams("star eats crate")
+	unconstrained, _ := rs.GetAt(0)
+	rs.SetConstraints(Constraints{MaxWords: 2})
+	if rs.Constraints().MaxWords != 2 {
+		t.Fatal("Constraints not set")
+	}
+
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		if len(strings.Fields(r)) > 2 {
+			t.Errorf("Result %q has too many words", r)
+		}
+	}
+
+	// Changing input keeps the constraints, and going back to the
+	// unconstrained search must not reuse the constrained state.
+	rs.FindAnagrams("cats")
+	if rs.Constraints().MaxWords != 2 {
+		t.Error("Constraints lost when input changed")
+	}
+	rs.FindAnagrams("star eats crate")
+	rs.SetConstraints(Constraints{})
+	if r, _ := rs.GetAt(0); r != unconstrained {
+		t.Errorf("Expected the unconstrained results back, got %q", r)
+	}
+	rs.Abort()
+}
+
+func TestResultSetRanked(t *testing.T) {
+	rs := newTestResultSet()
+
+	rs.FindAnagrams("star eats crate")
+	rs.SetOrder(RankedOrder)
+	if rs.Order() != RankedOrder {
+		t.Fatal("Order not set")
+	}
+
+	scorer := rs.Scorer()
+	previous := 0.0
+	count := 0
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		score := scorer.Score(r)
+		if i > 0 && score > previous {
+			t.Errorf("Result %d %q scores %f, more than the one before it", i, r, score)
+		}
+		previous = score
+		count += 1
+	}
+	if count == 0 || count > max_ranked_results {
+		t.Errorf("Unexpected number of ranked results: %d", count)
+	}
+
+	// The best result must be at least as good as anything the search finds.
+	best, _ := rs.GetAt(0)
+	for r := range FindAnagrams("star eats crate", nil, mediumDict) {
+		if Normalize(r) != Normalize("star eats crate") && scorer.Score(r) > scorer.Score(best) {
+			t.Errorf("%q scores higher than top result %q", r, best)
+			break
+		}
+	}
+
+	rs.SetOrder(DictionaryOrder)
+	if r, _ := rs.GetAt(0); r == "" {
+		t.Error("No results after switching back to dictionary order")
+	}
+	rs.Abort()
+}
+
+func TestResultSetPhraseIsOneWord(t *testing.T) {
+	places, _ := ParseDictionary("places", []byte(`["New York"]`))
+	dict := MergeDictionaries(nil, mediumDict, places)
+	private := NewDictionary("Private")
+	private.Enabled = false
+	rs := NewResultSet([]*Dictionary{dict}, []*Dictionary{}, private, 0)
+
+	rs.FindAnagrams("new york cats")
+	rs.SetConstraints(Constraints{MaxWords: 2})
+	found := false
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		if strings.Contains(r, "New_York") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Expected a two word result using the phrase")
+	}
+
+	for _, wc := range rs.TopNWords(1000) {
+		if wc.Word == "New" || wc.Word == "York" {
+			t.Errorf("Phrase counted as separate word %q", wc.Word)
+		}
+	}
+	rs.Abort()
+}
+
+func TestResultSetPhraseIsNotItsWords(t *testing.T) {
+	phrases, _ := ParseDictionary("phrases", []byte(`["tea set"]`))
+	dict := MergeDictionaries(nil, mediumDict, phrases)
+	private := NewDictionary("Private")
+	private.Enabled = false
+	rs := NewResultSet([]*Dictionary{dict}, []*Dictionary{}, private, 0)
+
+	// "tea_set rats" and "tea set rats" read the same but are different
+	// dictionary entries, so both are results and both are counted.
+	rs.FindAnagrams("star tea set")
+	rs.SetConstraints(Constraints{MaxWords: 3})
+	total, err := rs.TotalCount(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.FetchTo(1000000)
+	phrase, words := false, false
+	for i := 0; i < rs.Count(); i++ {
+		r, _ := rs.GetAt(i)
+		switch Normalize(r) {
+		case "rats set tea":
+			if strings.Contains(r, "_") {
+				phrase = true
+			} else {
+				words = true
+			}
+		}
+	}
+	if !phrase || !words {
+		t.Errorf("Expected rats with both the phrase and its words, got %v and %v", phrase, words)
+	}
+	if total != rs.Count() {
+		t.Errorf("Counted %d results, fetched %d", total, rs.Count())
+	}
+	rs.Abort()
+}
+
+func TestResultSetDedupOverlappingInclusions(t *testing.T) {
+	rs := newTestResultSet()
+	included := []string{"eats", "cat", "stare"}
+
+	// Without dedup, "eats cat ..." from the first phrase and "cat eats ..."
+	// from the second are the same anagram.
+	want := make(map[string]bool)
+	raw := 0
+	for r := range FindAnagrams("star eats crate", included, mediumDict) {
+		raw += 1
+		if Normalize(r) != Normalize("star eats crate") {
+			want[Normalize(r)] = true
+		}
+	}
+	if raw <= len(want) {
+		t.Fatalf("Test inclusions don't overlap: %d raw, %d unique", raw, len(want))
+	}
+
+	rs.FindAnagrams("star eats crate")
+	rs.SetInclusions(included)
+	got := make(map[string]bool)
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		if got[Normalize(r)] {
+			t.Errorf("Duplicate result %q", r)
+		}
+		got[Normalize(r)] = true
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected %d unique results, got %d", len(want), len(got))
+	}
+	rs.Abort()
+}
+
+func TestResultSetLeftover(t *testing.T) {
+	rs := newTestResultSet()
+
+	rs.FindAnagrams("star eats")
+	if rs.LeftoverAt(0) != "" {
+		t.Errorf("Full anagram shouldn't have leftover letters, got %q", rs.LeftoverAt(0))
+	}
+
+	rs.SetConstraints(Constraints{Leftover: "e", MaxLeftover: 1})
+	partial := 0
+	for i := 0; ; i++ {
+		r, ok := rs.GetAt(i)
+		if !ok {
+			break
+		}
+		leftover := rs.LeftoverAt(i)
+		if !strings.Contains(leftover, "e") || len(leftover) > 2 {
+			t.Errorf("Result %q leaves %q", r, leftover)
+		}
+		if len(leftover) == 2 {
+			partial += 1
+		}
+	}
+	if partial == 0 {
+		t.Error("Expected results with a spare letter")
+	}
+	rs.Abort()
+}
+
+func TestResultSetProgress(t *testing.T) {
+	rs := newTestResultSet()
+	rs.FindAnagrams("star eats crate")
+	if p := rs.Progress(); p.Fraction != 0 || p.EstimatedTotal != -1 || p.ETA != -1 {
+		t.Errorf("Expected no estimates before fetching, got %+v", p)
+	}
+
+	rs.FetchTo(100)
+	p := rs.Progress()
+	if p.Fraction <= 0 || p.Fraction >= 1 {
+		t.Errorf("Expected a partly done search, got %+v", p)
+	}
+	if p.EstimatedTotal < rs.Count() || p.ETA < 0 {
+		t.Errorf("Expected estimates after %d results, got %+v", rs.Count(), p)
+	}
+
+	// A resumed search has to catch up with the results it already has
+	// before they say anything about the rest.
+	rs.stateLock.Lock()
+	rs.state.startSearch()
+	rs.state.progress.add(p.Fraction / 2)
+	rs.stateLock.Unlock()
+	if q := rs.Progress(); q.Fraction < min_estimate_fraction || q.EstimatedTotal != -1 || q.ETA != -1 {
+		t.Errorf("Expected no estimates while skipping, got %+v", q)
+	}
+	count := rs.Count()
+	rs.FetchTo(count + 1)
+	if rs.Count() != count+1 {
+		t.Fatalf("Expected to pick up after %d results, have %d", count, rs.Count())
+	}
+	if q := rs.Progress(); q.EstimatedTotal < rs.Count() || q.ETA < 0 {
+		t.Errorf("Expected estimates once caught up, got %+v", q)
+	}
+
+	rs.FetchTo(1000000)
+	if p := rs.Progress(); p.Fraction != 1 || p.EstimatedTotal != rs.Count() || p.ETA != 0 {
+		t.Errorf("Expected a finished search, got %+v with %d results", p, rs.Count())
+	}
+	rs.Abort()
+}
+
+func TestResultSetTotalCount(t *testing.T) {
+	rs := newTestResultSet()
+	rs.SetConstraints(Constraints{MaxWords: 3})
+	rs.FindAnagrams("star eats crate")
+	total, err := rs.TotalCount(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs.FetchTo(1000000)
+	if total != rs.Count() {
+		t.Errorf("Counted %d results, fetched %d", total, rs.Count())
+	}
+
+	// The input is an anagram of itself but never a result.
+	rs.FindAnagrams("star eats")
+	total, _ = rs.TotalCount(context.Background())
+	rs.FetchTo(1000000)
+	if total != rs.Count() {
+		t.Errorf("Counted %d results for star eats, fetched %d", total, rs.Count())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rs.FindAnagrams("acres taste")
+	if _, err := rs.TotalCount(ctx); err == nil {
+		t.Error("Expected an error from a cancelled count")
+	}
+	rs.Abort()
+}
+
+func TestResultSetShuffled(t *testing.T) {
+	rs := newTestResultSet()
+	rs.FindAnagrams("star eats crate")
+	rs.FetchTo(1000000)
+	var want []string
+	for i := 0; i < rs.Count(); i++ {
+		result, _ := rs.GetAt(i)
+		want = append(want, result)
+	}
+
+	shuffled := func(seed uint64) []string {
+		rs.SetShuffleSeed(seed)
+		rs.SetOrder(ShuffledOrder)
+		rs.FetchTo(1000000)
+		var results []string
+		for i := 0; i < rs.Count(); i++ {
+			result, _ := rs.GetAt(i)
+			results = append(results, result)
+		}
+		rs.SetOrder(DictionaryOrder)
+		return results
+	}
+
+	first := shuffled(1)
+	if slices.Equal(first, want) {
+		t.Error("Shuffled results came in dictionary order")
+	}
+	sorted := slices.Clone(first)
+	slices.Sort(sorted)
+	slices.Sort(want)
+	if !slices.Equal(sorted, want) {
+		t.Errorf("Shuffling gave %d results, expected %d", len(sorted), len(want))
+	}
+	if again := shuffled(1); !slices.Equal(first, again) {
+		t.Error("The same seed gave a different order")
+	}
+	if other := shuffled(2); slices.Equal(first, other) {
+		t.Error("Different seeds gave the same order")
+	}
+	rs.Abort()
+}
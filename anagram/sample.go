@@ -0,0 +1,449 @@
+package anagram
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	// Searches with no more results than this are found whole and shuffled
+	// rather than sampled, as sampling would spend ever longer finding the
+	// last few.
+	max_shuffled_results = 20000
+	// Sampling stops after this many picks in a row that find nothing new.
+	max_sample_misses = 1000
+	// A walk that hasn't found anything after trying this many branches is
+	// given up and started again, rather than left lost in a big search
+	// that has few results.
+	max_walk_steps = 1 << 12
+)
+
+// SampleAnagrams streams the results FindAnagramsWithOptions would produce
+// in a random order, so the first ones aren't all the same few words. The
+// order depends only on seed and the arguments, so a search can be
+// repeated exactly.
+//
+// Results are picked using the counts CountAnagrams works out, so each
+// result not yet produced is as likely as any other to come next. When
+// there are too many letters to count, it searches with the words in a
+// random order instead, favouring words with bigger searches after them,
+// which is only roughly even. Each result comes at most once.
+//
+// With counts the stream ends once every result has come: when
+// max_sample_misses picks in a row find nothing new, the few results left
+// are found by searching and sent in a random order. Without counts there's
+// no telling how many results are left, so the stream just ends there,
+// leaving out whatever the walks didn't find.
+//
+// Workers and Unordered are ignored. Progress follows the share of the
+// results produced, when they can be counted.
+func SampleAnagrams(ctx context.Context, input string, include []string, dictionary *Dictionary, opts SearchOptions, seed uint64) <-chan string {
+	outputChan := make(chan string, 10)
+
+	s := &sampler{
+		searcher: newSearcher(ctx, dictionary, opts),
+		rng:      rand.New(rand.NewPCG(seed, seed)),
+		seen:     make(map[string]struct{}),
+	}
+	go s.sampleAnagrams(input, include, dictionary, outputChan)
+
+	return outputChan
+}
+
+type sampler struct {
+	*searcher
+	rng    *rand.Rand
+	seen   map[string]struct{}
+	sizes  map[sampleKey][]float64 // branchSizes of branches walked before
+	misses int
+	steps  int // branches tried by the current walk
+}
+
+// sampleStart is a place the search starts from, see eachStart.
+type sampleStart struct {
+	phrase string
+	words  int
+	target *RuneCluster
+	dict   annotatedDict
+}
+
+// sampleEnd is a part of a start's target and a slot of word counts that
+// results can use up, along with how many results do.
+type sampleEnd struct {
+	start *sampleStart
+	part  RuneCluster
+	slot  int
+	count float64
+}
+
+func (s *sampler) sampleAnagrams(input string, include []string, dictionary *Dictionary, output chan<- string) {
+	defer func() {
+		if s.ctx.Err() == nil {
+			s.opts.Progress.finish()
+		}
+		close(output)
+	}()
+
+	if strings.TrimSpace(input) == "" {
+		return
+	}
+
+	filtered, target, ok := s.prepare(input, dictionary)
+	if ok {
+		filtered, ok = s.preparePattern(filtered)
+	}
+	if !ok {
+		return
+	}
+	var starts []*sampleStart
+	s.eachStart(include, filtered, target, func(phrase string, words int, target *RuneCluster, dict annotatedDict) {
+		starts = append(starts, &sampleStart{phrase, words, target, dict})
+	})
+
+	t, err := newCountTable(target, s.opts.Constraints)
+	if err == nil && s.pattern != nil {
+		err = ErrCantCountPatterns
+	}
+	if err != nil {
+		log.Println("Sampling without counts:", err)
+		s.walkAll(starts, output)
+		return
+	}
+	for _, dp := range filtered {
+		if s.ctx.Err() != nil {
+			return
+		}
+		t.add(dp.cluster)
+	}
+
+	var ends []sampleEnd
+	total := 0
+	for _, start := range starts {
+		t.ends(start.words, start.target, func(part *RuneCluster, slot int, n int) {
+			ends = append(ends, sampleEnd{start, *part, slot, float64(n)})
+			total = addCounts(total, n)
+		})
+	}
+
+	if total <= max_shuffled_results {
+		s.shuffleAll(input, include, dictionary, output)
+		return
+	}
+	s.sampleAll(t, ends, total, output)
+	if s.misses >= max_sample_misses && s.ctx.Err() == nil {
+		s.shuffleAll(input, include, dictionary, output)
+	}
+}
+
+// emitNew sends result unless it's been sent before, reporting whether it was
+// new.
+func (s *sampler) emitNew(result string, output chan<- string) bool {
+	if s.wasSeen(result) {
+		s.misses += 1
+		return false
+	}
+	s.seen[result] = struct{}{}
+	s.misses = 0
+	select {
+	case output <- result:
+	case <-s.ctx.Done():
+	}
+	return true
+}
+
+// shuffleAll finds every result not sent yet and sends them in a random
+// order.
+func (s *sampler) shuffleAll(input string, include []string, dictionary *Dictionary, output chan<- string) {
+	opts := s.opts
+	opts.Progress = nil
+	var results []string
+	for result := range FindAnagramsWithOptions(s.ctx, input, include, dictionary, opts) {
+		if !s.wasSeen(result) {
+			results = append(results, result)
+		}
+	}
+	s.rng.Shuffle(len(results), func(i, j int) {
+		results[i], results[j] = results[j], results[i]
+	})
+	for _, result := range results {
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.emitNew(result, output)
+	}
+}
+
+// sampleAll picks results from the counted ends until they've all been
+// sent, or until max_sample_misses picks in a row have found nothing new.
+// Each pick is equally likely to be any result, so the ones not sent yet
+// are too.
+func (s *sampler) sampleAll(t *countTable, ends []sampleEnd, total int, output chan<- string) {
+	weight := 0.0
+	for _, end := range ends {
+		weight += end.count
+	}
+
+	for sent := 0; sent < total && s.misses < max_sample_misses && s.ctx.Err() == nil; {
+		pick := s.rng.Float64() * weight
+		end := &ends[len(ends)-1]
+		for i := range ends {
+			if pick < ends[i].count {
+				end = &ends[i]
+				break
+			}
+			pick -= ends[i].count
+		}
+
+		result := end.start.phrase
+		for _, word := range t.sample(s.rng, s.words, end.part, end.slot) {
+			result = join(result, word)
+		}
+		if s.emitNew(result, output) {
+			sent += 1
+			s.opts.Progress.add(1 / float64(total))
+		}
+	}
+}
+
+// sample picks one of the bunches of words making part with the words in
+// slot, each as likely as any other, and returns them in dictionary order.
+// words must be the words the table was filled in with.
+//
+// Taking any one letter of the part to belong to one of k copies of a word
+// w, the bunches making part number
+//
+//	|part| * bunches(part) = sum over w and k of |w| * bunches(part - k*w)
+//
+// so picking w and k in proportion to their term, then a bunch for what's
+// left, picks each bunch equally often.
+func (t *countTable) sample(rng *rand.Rand, words annotatedDict, part RuneCluster, slot int) []string {
+	// Without a maximum, the last slot is every count from there up, and so
+	// is anything taken from it.
+	exact := t.c.MaxWords > 0 || slot < t.words-1
+	value := func(rest *RuneCluster, slot int) float64 {
+		index := t.index(rest) * t.words
+		if exact {
+			return float64(t.counts[index+slot])
+		}
+		n := 0
+		for w := slot; w < t.words; w++ {
+			n = addCounts(n, t.counts[index+w])
+		}
+		return float64(n)
+	}
+	// each calls term with every w, k and the part and slot they leave.
+	each := func(term func(i, k int, rest *RuneCluster, slot int) bool) {
+		for i := range words {
+			w := words[i].cluster
+			rest := part
+			for k := 1; w.SubSetOf(&rest) && (!exact || k <= slot); k++ {
+				for l := range rest {
+					rest[l] -= w[l]
+				}
+				if !term(i, k, &rest, max(slot-k, 0)) {
+					return
+				}
+			}
+		}
+	}
+
+	var picked []int
+	for !part.IsEmpty() {
+		total := 0.0
+		each(func(i, k int, rest *RuneCluster, slot int) bool {
+			total += float64(words[i].letters) * value(rest, slot)
+			return true
+		})
+		if total == 0 {
+			panic("sampled a part no words make") // this shouldn't be possible
+		}
+
+		pick := rng.Float64() * total
+		each(func(i, k int, rest *RuneCluster, restSlot int) bool {
+			term := float64(words[i].letters) * value(rest, restSlot)
+			if term == 0 || pick >= term {
+				pick -= term
+				return true
+			}
+			for range k {
+				picked = append(picked, i)
+			}
+			part, slot = *rest, restSlot
+			return false
+		})
+	}
+
+	slices.Sort(picked)
+	result := make([]string, len(picked))
+	for n, i := range picked {
+		result[n] = words[i].Word
+	}
+	return result
+}
+
+type sampleKey struct {
+	deadEndKey
+	start int
+}
+
+// walkAll picks results by searching from a random start, trying the words
+// in a random order that favours the ones with bigger searches after them.
+// Each walk stops at the first result that hasn't been sent yet, or after
+// max_walk_steps branches.
+func (s *sampler) walkAll(starts []*sampleStart, output chan<- string) {
+	if len(starts) == 0 {
+		return
+	}
+	s.sizes = make(map[sampleKey][]float64)
+	dead := s.newDeadEnds()
+	for s.misses < max_sample_misses && s.ctx.Err() == nil {
+		start := starts[s.rng.IntN(len(starts))]
+		s.steps = 0
+		if result, fresh, _ := s.walk(start.phrase, start.words, start.target, start.dict, dead); fresh {
+			s.emitNew(result, output)
+		} else {
+			s.misses += 1
+		}
+	}
+}
+
+// walk looks for a phrase not sent yet that starts with current and uses up
+// target with words from dict, reporting whether it found one, and whether
+// it found any phrase at all. Branches with none are remembered in dead, if
+// it isn't nil, like findTuples does.
+func (s *sampler) walk(current string, words int, target *RuneCluster, dict annotatedDict, dead *deadEnds) (string, bool, bool) {
+	s.steps += 1
+	if s.ctx.Err() != nil || s.steps > max_walk_steps {
+		return current, false, true // gave up, so it's not known to be dead
+	}
+	if s.pattern != nil && !s.pattern.allows(current) {
+		return current, false, false
+	}
+
+	canStop := current != "" && s.opts.Constraints.allowsCount(words)
+	if target.IsEmpty() {
+		return s.stop(current, canStop)
+	}
+	canStop = canStop && s.isPartial(target)
+	if len(dict) == 0 || !s.canFinish(words, target, dict) {
+		return s.stop(current, canStop)
+	}
+
+	if s.mustBeLastWord(words, target, dict) {
+		s.once.Do(func() {
+			s.exact = newSignatureIndex(s.words)
+		})
+		var last []string
+		s.exact.lookup(target, func(dp *dictPair) {
+			if dict.contains(dp.Word) {
+				last = append(last, dp.Word)
+			}
+		})
+		slices.Sort(last) // lookup's order isn't fixed
+		s.rng.Shuffle(len(last), func(i, j int) {
+			last[i], last[j] = last[j], last[i]
+		})
+		live := false
+		for _, word := range last {
+			result, fresh, found := s.stop(join(current, word), true)
+			if fresh {
+				return result, true, true
+			}
+			live = live || found
+		}
+		if result, fresh, found := s.stop(current, canStop); found {
+			return result, fresh, true
+		}
+		return current, false, live
+	}
+
+	var key sampleKey
+	remember := dead != nil && len(dict) >= min_memo_dict
+	if remember {
+		key.deadEndKey, remember = s.deadEndKey(words, target)
+		key.start = s.words.position(dict[0].Word)
+	}
+	if remember && dead.has(key.deadEndKey, key.start) {
+		return current, false, false
+	}
+
+	// Sorting by a random number to the power of one over a branch's size
+	// puts the branches in a random order where each comes first in
+	// proportion to its size.
+	sizes := s.branchSizes(key, remember, target, dict)
+	order := make([]int, len(sizes))
+	keys := make([]float64, len(sizes))
+	for i, size := range sizes {
+		order[i] = i
+		keys[i] = math.Pow(s.rng.Float64(), 1/size)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(keys[b], keys[a])
+	})
+
+	stopped, fresh, live := s.stop(current, canStop)
+	if fresh && s.rng.IntN(len(sizes)+1) == 0 {
+		return stopped, true, true
+	}
+	for _, index := range order {
+		if s.ctx.Err() != nil || s.steps > max_walk_steps {
+			return current, false, true
+		}
+		trial, newTarget, newDict := extend(current, target, dict, index)
+		result, fresh, found := s.walk(trial, words+1, newTarget, newDict, dead)
+		if fresh {
+			return result, true, true
+		}
+		live = live || found
+	}
+	if !live && remember && s.ctx.Err() == nil {
+		dead.add(key.deadEndKey, key.start)
+	}
+	if fresh {
+		return stopped, true, true
+	}
+	return current, false, live
+}
+
+// stop ends a walk at current if canStop is set, putting it in the
+// pattern's order if there is one, and reports as walk does.
+func (s *sampler) stop(current string, canStop bool) (string, bool, bool) {
+	if !canStop {
+		return current, false, false
+	}
+	if s.pattern != nil {
+		arranged, ok := s.pattern.arrange(current)
+		if !ok {
+			return current, false, false
+		}
+		current = arranged
+	}
+	return current, !s.wasSeen(current), true
+}
+
+// wasSeen reports whether result has been sent already.
+func (s *sampler) wasSeen(result string) bool {
+	_, ok := s.seen[result]
+	return ok
+}
+
+// branchSizes is the estimated size of each branch of the search for
+// target with dict, remembered under key if remember is set.
+func (s *sampler) branchSizes(key sampleKey, remember bool, target *RuneCluster, dict annotatedDict) []float64 {
+	if remember {
+		if sizes, ok := s.sizes[key]; ok {
+			return sizes
+		}
+	}
+
+	sizes := branchSizes(target, dict, s.countBranches(target, suffixSums(dict, target)))
+	if remember && len(s.sizes) < default_memo_size {
+		s.sizes[key] = sizes
+	}
+	return sizes
+}
@@ -0,0 +1,202 @@
+package anagram
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// takeSamples reads up to n results from SampleAnagrams, in order.
+func takeSamples(input string, dict *Dictionary, opts SearchOptions, seed uint64, n int) []string {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var results []string
+	for result := range SampleAnagrams(ctx, input, nil, dict, opts, seed) {
+		results = append(results, result)
+		if len(results) == n {
+			break
+		}
+	}
+	return results
+}
+
+func TestSampleAnagrams(t *testing.T) {
+	cases := []struct {
+		input    string
+		included []string
+		c        Constraints
+	}{
+		{"star eats crate", nil, Constraints{}},
+		{"star eats crate", nil, Constraints{MaxWords: 3}},
+		{"star eats crate", nil, Constraints{MaxLeftover: 1}},
+		{"star eats crate", []string{"cat", "rat"}, Constraints{}},
+		{"", nil, Constraints{}},
+	}
+
+	for _, c := range cases {
+		opts := SearchOptions{Constraints: c.c}
+		want := collectAll(FindAnagramsWithOptions(context.Background(), c.input, c.included, mediumDict, opts))
+		var first []string
+		for result := range SampleAnagrams(context.Background(), c.input, c.included, mediumDict, opts, 1) {
+			first = append(first, result)
+		}
+		got := slices.Clone(first)
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("Sampling %q with %v and %+v found %d results, the search found %d", c.input, c.included, c.c, len(got), len(want))
+		}
+
+		again := collectAll(SampleAnagrams(context.Background(), c.input, c.included, mediumDict, opts, 1))
+		if !slices.Equal(got, again) {
+			t.Errorf("Sampling %q twice with the same seed gave different results", c.input)
+		}
+	}
+
+	a := takeSamples("star eats crate", mediumDict, SearchOptions{}, 1, 0)
+	b := takeSamples("star eats crate", mediumDict, SearchOptions{}, 1, 0)
+	c := takeSamples("star eats crate", mediumDict, SearchOptions{}, 2, 0)
+	if !slices.Equal(a, b) {
+		t.Error("The same seed gave a different order")
+	}
+	if slices.Equal(a, c) {
+		t.Error("Different seeds gave the same order")
+	}
+}
+
+func TestCountTableSample(t *testing.T) {
+	cases := []Constraints{
+		{},
+		{MaxWords: 3},
+		{MinWords: 4},
+		{MaxLeftover: 2},
+	}
+
+	for _, c := range cases {
+		opts := SearchOptions{Constraints: c}
+		valid := make(map[string]int)
+		for _, result := range collectAll(FindAnagramsWithOptions(context.Background(), "star eats crate", nil, mediumDict, opts)) {
+			valid[result] = 0
+		}
+
+		s := &searcher{ctx: context.Background(), opts: opts}
+		filtered, target, _ := s.prepare("star eats crate", mediumDict)
+		table, err := newCountTable(target, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dp := range filtered {
+			table.add(dp.cluster)
+		}
+
+		// Every result is as likely as any other, so taking plenty of
+		// samples from each end should find them all, about evenly.
+		rng := rand.New(rand.NewPCG(1, 1))
+		samples := 0
+		table.ends(0, target, func(part *RuneCluster, slot int, n int) {
+			for range 50 * n {
+				result := ""
+				for _, word := range table.sample(rng, s.words, *part, slot) {
+					result = join(result, word)
+				}
+				if _, ok := valid[result]; !ok {
+					t.Fatalf("Sampled %q with %+v, which the search doesn't find", result, c)
+				}
+				valid[result] += 1
+				samples += 1
+			}
+		})
+
+		for result, n := range valid {
+			if n < 10 || n > 150 {
+				t.Errorf("Sampled %q %d times out of %d with %+v, expected about 50", result, n, samples, c)
+			}
+		}
+	}
+}
+
+func TestSampleAnagramsCounted(t *testing.T) {
+	// Sampling only starts once there are too many results to shuffle, so
+	// this needs a real dictionary.
+	mainDicts, _, err := ReadDictionaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := mainDicts[0]
+
+	valid := make(map[string]bool)
+	for result := range FindAnagrams("star eats crate", nil, dict) {
+		valid[result] = true
+	}
+	if len(valid) <= max_shuffled_results {
+		t.Fatalf("Only %d results, which would be shuffled", len(valid))
+	}
+
+	progress := &Progress{}
+	samples := takeSamples("star eats crate", dict, SearchOptions{Progress: progress}, 7, 500)
+	seen := make(map[string]bool)
+	for _, result := range samples {
+		if !valid[result] {
+			t.Errorf("Sampled %q, which the search doesn't find", result)
+		}
+		if seen[result] {
+			t.Errorf("Sampled %q twice", result)
+		}
+		seen[result] = true
+	}
+	if len(samples) != 500 {
+		t.Errorf("Expected 500 samples, got %d", len(samples))
+	}
+	if f := progress.Fraction(); f <= 0 || f >= 1 {
+		t.Errorf("Expected partial progress, got %f", f)
+	}
+
+	if again := takeSamples("star eats crate", dict, SearchOptions{}, 7, 500); !slices.Equal(samples, again) {
+		t.Error("The same seed gave a different order")
+	}
+
+	// Sampling to the end gives every result, including the last few that
+	// picking at random keeps missing.
+	all := collectAll(SampleAnagrams(context.Background(), "star eats crate", nil, dict, SearchOptions{}, 7))
+	if len(all) != len(valid) {
+		t.Errorf("Sampled %d results to the end, the search found %d", len(all), len(valid))
+	}
+	for _, result := range all {
+		if !valid[result] {
+			t.Errorf("Sampled %q, which the search doesn't find", result)
+		}
+	}
+}
+
+func TestSampleAnagramsWalk(t *testing.T) {
+	mainDicts, _, err := ReadDictionaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := mainDicts[0]
+
+	// Too many different letters to count.
+	input := "the quick brown fox jumps over the lazy dog"
+	if _, err := CountAnagrams(context.Background(), input, nil, dict, SearchOptions{}); err != ErrTooManyLetters {
+		t.Fatalf("Expected ErrTooManyLetters, got %v", err)
+	}
+
+	samples := takeSamples(input, dict, SearchOptions{}, 3, 5)
+	if len(samples) != 5 {
+		t.Errorf("Expected 5 samples, got %d", len(samples))
+	}
+	seen := make(map[string]bool)
+	for _, result := range samples {
+		if left := LatinAlphabet.Leftover(input, result); left == nil || !left.IsEmpty() {
+			t.Errorf("Sampled %q, which isn't an anagram of %q", result, input)
+		}
+		if seen[result] {
+			t.Errorf("Sampled %q twice", result)
+		}
+		seen[result] = true
+	}
+
+	if again := takeSamples(input, dict, SearchOptions{}, 3, 5); !slices.Equal(samples, again) {
+		t.Error("The same seed gave a different order")
+	}
+}
@@ -0,0 +1,166 @@
+package anagram
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+)
+
+// Scorer rates anagrams so the best ones can be shown first. Each part of
+// the score is weighted separately:
+//
+//   - every word costs WordPenalty, so fewer words score higher
+//   - LengthWeight times the sum of squared word lengths over the total
+//     letter count, which favours a few long words over many short ones
+//     (the same idea as the metric TopNWords uses)
+//   - FrequencyWeight times the average of Frequency over the words, when
+//     there's frequency data to go on
+//   - the Bonus for each word, e.g. for names and places
+type Scorer struct {
+	WordPenalty     float64
+	LengthWeight    float64
+	FrequencyWeight float64
+	Frequency       func(word string) float64 // commonness from 0 to 1, nil if unknown
+	Bonus           map[string]float64        // keyed by lower case word
+}
+
+// neutralFrequency is used for words that have no frequency data, so they're
+// neither favoured nor penalized against words that do.
+const neutralFrequency = 0.5
+
+// DictionaryFrequency returns a Scorer.Frequency function backed by the
+// dictionary's word data, or nil if it has none.
+func DictionaryFrequency(d *Dictionary) func(string) float64 {
+	if d == nil {
+		return nil
+	}
+	d.ensureLoaded()
+	if d.Info == nil {
+		return nil
+	}
+	return func(word string) float64 {
+		if f, ok := d.Frequency(word); ok {
+			return f
+		}
+		return neutralFrequency
+	}
+}
+
+func NewScorer() *Scorer {
+	return &Scorer{
+		WordPenalty:     1.0,
+		LengthWeight:    1.0,
+		FrequencyWeight: 4.0,
+		Bonus:           make(map[string]float64),
+	}
+}
+
+// AddBonus gives every word of d the bonus score.
+func (s *Scorer) AddBonus(d *Dictionary, bonus float64) {
+	d.ensureLoaded()
+	for _, word := range d.Words {
+		s.Bonus[strings.ToLower(word)] += bonus
+	}
+}
+
+func (s *Scorer) Score(result string) float64 {
+	words := strings.Split(result, " ")
+
+	var score, squares, frequency float64
+	letters := 0
+	count := 0
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		count += 1
+		n := NewRuneCluster(word).Size()
+		letters += n
+		squares += float64(n * n)
+		if s.Frequency != nil {
+			frequency += s.Frequency(word)
+		}
+		score += s.Bonus[strings.ToLower(word)]
+	}
+	if count == 0 {
+		return 0
+	}
+
+	score -= s.WordPenalty * float64(count)
+	if letters > 0 {
+		score += s.LengthWeight * squares / float64(letters)
+	}
+	if s.Frequency != nil {
+		score += s.FrequencyWeight * frequency / float64(count)
+	}
+
+	return score
+}
+
+type ScoredResult struct {
+	Result string
+	Score  float64
+}
+
+// better orders results by score, breaking ties alphabetically so the
+// ranking doesn't depend on the order results arrive in.
+func (a ScoredResult) better(b ScoredResult) bool {
+	if a.Score == b.Score {
+		return a.Result < b.Result
+	}
+	return a.Score > b.Score
+}
+
+// scoredHeap is a min-heap, so the worst of the kept results is on top and
+// can be replaced cheaply.
+type scoredHeap []ScoredResult
+
+func (h scoredHeap) Len() int           { return len(h) }
+func (h scoredHeap) Less(i, j int) bool { return h[j].better(h[i]) }
+func (h scoredHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *scoredHeap) Push(x any)        { *h = append(*h, x.(ScoredResult)) }
+func (h *scoredHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopK keeps the K best results seen so far in O(log K) per result.
+type TopK struct {
+	k int
+	h scoredHeap
+}
+
+func NewTopK(k int) *TopK {
+	return &TopK{k, make(scoredHeap, 0, k)}
+}
+
+// Add offers a result, returning true if it's among the best so far.
+func (t *TopK) Add(result string, score float64) bool {
+	sr := ScoredResult{result, score}
+	if len(t.h) < t.k {
+		heap.Push(&t.h, sr)
+		return true
+	}
+	if t.k == 0 || !sr.better(t.h[0]) {
+		return false
+	}
+	t.h[0] = sr
+	heap.Fix(&t.h, 0)
+	return true
+}
+
+func (t *TopK) Len() int {
+	return len(t.h)
+}
+
+// Sorted returns the kept results, best first.
+func (t *TopK) Sorted() []ScoredResult {
+	sorted := make([]ScoredResult, len(t.h))
+	copy(sorted, t.h)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].better(sorted[j])
+	})
+	return sorted
+}
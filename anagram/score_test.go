@@ -0,0 +1,63 @@
+package anagram
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScorerPrefersFewerLongerWords(t *testing.T) {
+	s := NewScorer()
+	if s.Score("stare cats") <= s.Score("star eats c") {
+		t.Error("Two long words should beat three words with a single letter")
+	}
+	if s.Score("") != 0 {
+		t.Error("Empty result should score 0")
+	}
+}
+
+func TestScorerFrequencyAndBonus(t *testing.T) {
+	s := NewScorer()
+	base := s.Score("rat tar")
+
+	s.Frequency = func(word string) float64 {
+		if word == "rat" {
+			return 1.0
+		}
+		return 0.0
+	}
+	if s.Score("rat rat") <= s.Score("tar tar") {
+		t.Error("Common words should score higher")
+	}
+
+	s.Frequency = nil
+	places := &Dictionary{Name: "places", Words: []string{"Tar"}}
+	s.AddBonus(places, 2.0)
+	if got := s.Score("rat tar"); got != base+2.0 {
+		t.Errorf("Expected bonus of 2, got %f -> %f", base, got)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	top := NewTopK(3)
+	for i := 0; i < 10; i++ {
+		top.Add(fmt.Sprintf("r%d", i), float64(i%5))
+	}
+	if top.Len() != 3 {
+		t.Fatalf("Expected 3 kept results, got %d", top.Len())
+	}
+
+	sorted := top.Sorted()
+	expected := []string{"r4", "r9", "r3"}
+	for i, sr := range sorted {
+		if sr.Result != expected[i] {
+			t.Errorf("Result %d is %q, expected %q", i, sr.Result, expected[i])
+		}
+	}
+
+	if top.Add("worse", 0) {
+		t.Error("A low score shouldn't be kept")
+	}
+	if !top.Add("best", 10) || top.Sorted()[0].Result != "best" {
+		t.Error("A high score should be kept at the top")
+	}
+}
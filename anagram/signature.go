@@ -0,0 +1,84 @@
+package anagram
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// signatureIndex finds the entries of an annotatedDict that have exactly a
+// given set of letters, such as "listen", "silent" and "tinsel". Entries
+// with the same signature are chained together through next, in the order
+// they appear in the annotatedDict, which keeps building one down to two
+// allocations.
+type signatureIndex struct {
+	dict  annotatedDict
+	first map[uint64]int32
+	next  []int32 // the next entry with the same signature, -1 for none
+}
+
+func newSignatureIndex(ad annotatedDict) *signatureIndex {
+	si := &signatureIndex{ad, make(map[uint64]int32, len(ad)), make([]int32, len(ad))}
+	for i := len(ad) - 1; i >= 0; i-- {
+		key := ad[i].cluster.signature()
+		if j, ok := si.first[key]; ok {
+			si.next[i] = j
+		} else {
+			si.next[i] = -1
+		}
+		si.first[key] = int32(i)
+	}
+	return si
+}
+
+// lookup calls found with each entry that has exactly the letters of
+// target, in annotatedDict order.
+func (si *signatureIndex) lookup(target *RuneCluster, found func(dp *dictPair)) {
+	i, ok := si.first[target.signature()]
+	for ok && i >= 0 {
+		if si.dict[i].cluster.Equals(target) { // signatures can collide
+			found(&si.dict[i])
+		}
+		i = si.next[i]
+	}
+}
+
+// signatureLock guards the signature indexes cached in dictionaries, which
+// ExactAnagrams can build from any goroutine.
+var signatureLock sync.Mutex
+
+// getSignatureIndex returns the dictionary's signature index, building it
+// on first use and again whenever its annotations are rebuilt.
+func getSignatureIndex(d *Dictionary) *signatureIndex {
+	ad := GetAnnotatedDict(d)
+
+	signatureLock.Lock()
+	defer signatureLock.Unlock()
+	if d.signatures == nil || !sameSlice(d.signatures.dict, ad) {
+		d.signatures = newSignatureIndex(ad)
+	}
+	return d.signatures
+}
+
+// ExactAnagrams returns the single entries of d that use exactly the letters
+// of input, in alphabetical order, leaving out input itself. They're found
+// with one lookup, so it's quick enough to call as the user types.
+func ExactAnagrams(input string, d *Dictionary) []string {
+	alphabet := d.alphabet()
+	target := alphabet.Cluster(input)
+	normalizedInput := alphabet.Normalize(input)
+
+	results := make([]string, 0)
+	if target.IsEmpty() {
+		return results
+	}
+	getSignatureIndex(d).lookup(target, func(dp *dictPair) {
+		if alphabet.Normalize(dp.Word) != normalizedInput {
+			results = append(results, dp.Word)
+		}
+	})
+	sort.Slice(results, func(i, j int) bool {
+		return strings.ToLower(results[i]) < strings.ToLower(results[j])
+	})
+	return results
+}
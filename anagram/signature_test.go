@@ -0,0 +1,102 @@
+package anagram
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestExactAnagrams(t *testing.T) {
+	got := ExactAnagrams("Tears", mediumDict)
+	if !cmpStringSlices(got, []string{"rates", "stare"}) {
+		t.Errorf("Expected rates and stare, got %v", got)
+	}
+	if got := ExactAnagrams("state", mediumDict); !cmpStringSlices(got, []string{"taste"}) {
+		t.Errorf("Expected taste, got %v", got)
+	}
+	if got := ExactAnagrams("zebra", mediumDict); len(got) != 0 {
+		t.Errorf("Expected nothing, got %v", got)
+	}
+
+	places, _ := ParseDictionary("places", []byte(`["New York"]`))
+	if got := ExactAnagrams("kenworthy", places); len(got) != 0 {
+		t.Errorf("Expected nothing, got %v", got)
+	}
+	if got := ExactAnagrams("wonky re", places); !cmpStringSlices(got, []string{"New_York"}) {
+		t.Errorf("Expected the phrase, got %v", got)
+	}
+	if got := ExactAnagrams("york new", places); len(got) != 0 {
+		t.Errorf("The input itself shouldn't be an anagram, got %v", got)
+	}
+}
+
+func TestExactAnagramsNoticesNewWords(t *testing.T) {
+	private := NewDictionary("Private")
+	private.Words = []string{"silent"}
+	if got := ExactAnagrams("listen", private); !cmpStringSlices(got, []string{"silent"}) {
+		t.Fatalf("Expected silent, got %v", got)
+	}
+
+	private.Words = []string{"silent", "tinsel", "enlist"}
+	if got := ExactAnagrams("listen", private); !cmpStringSlices(got, []string{"enlist", "silent", "tinsel"}) {
+		t.Errorf("Expected the new words, got %v", got)
+	}
+}
+
+// TestFindAnagramsLastWord checks the lookup that finishes phrases against
+// every pair of words, with and without a limit that forces it.
+func TestFindAnagramsLastWord(t *testing.T) {
+	input := "stare cats"
+	target := NewRuneCluster(input)
+
+	want := make([]string, 0)
+	for i, a := range mediumDict.Words {
+		for _, b := range mediumDict.Words[i:] {
+			if NewRuneCluster(a + b).Equals(target) {
+				want = append(want, Normalize(a+" "+b))
+			}
+		}
+	}
+	sort.Strings(want)
+
+	opts := SearchOptions{Workers: 1, Constraints: Constraints{MaxWords: 2}}
+	got := make([]string, 0)
+	for r := range FindAnagramsWithOptions(context.Background(), input, nil, mediumDict, opts) {
+		if len(strings.Fields(r)) == 2 {
+			got = append(got, Normalize(r))
+		}
+	}
+	sort.Strings(got)
+	if !cmpStringSlices(got, want) {
+		t.Errorf("Two word anagrams differ:\n%v\n%v", got, want)
+	}
+
+	unlimited := 0
+	for r := range FindAnagrams(input, nil, mediumDict) {
+		if len(strings.Fields(r)) == 2 {
+			unlimited += 1
+		}
+	}
+	if unlimited != len(want) {
+		t.Errorf("Expected %d two word anagrams without a limit, got %d", len(want), unlimited)
+	}
+}
+
+func TestResultSetExactAnagrams(t *testing.T) {
+	rs := newTestResultSet()
+
+	rs.FindAnagrams("tears")
+	if got := rs.ExactAnagrams(); !cmpStringSlices(got, []string{"rates", "stare"}) {
+		t.Errorf("Expected rates and stare, got %v", got)
+	}
+	rs.SetExclusions([]string{"stare"})
+	if got := rs.ExactAnagrams(); !cmpStringSlices(got, []string{"rates"}) {
+		t.Errorf("Excluded word still listed: %v", got)
+	}
+	rs.FindAnagrams("")
+	if got := rs.ExactAnagrams(); len(got) != 0 {
+		t.Errorf("Expected nothing for no input, got %v", got)
+	}
+	rs.Abort()
+}
@@ -0,0 +1,479 @@
+package anagram
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+)
+
+// Mode is the kind of wordplay a search looks for. Every mode uses the same
+// dictionaries, leaves out the words Constraints rules out and streams its
+// results over a channel.
+type Mode int
+
+const (
+	// AnagramMode rearranges all the letters of the input into phrases.
+	AnagramMode Mode = iota
+	// PalindromeMode finds anagrams of the input whose letters read the
+	// same backwards.
+	PalindromeMode
+	// HiddenWordMode finds words made of the letters of a run of the
+	// input's letters, which can cross the spaces between its words.
+	HiddenWordMode
+	// SpoonerismMode swaps the leading consonants of two of the input's
+	// words, when that makes two other words.
+	SpoonerismMode
+	// LadderMode finds the shortest ways from the input's first word to its
+	// second, changing one letter at a time and making a word at each step.
+	LadderMode
+	// CrypticMode finds anagram fodder for a cryptic crossword answer that
+	// doesn't give the answer away, see FindFodder.
+	CrypticMode
+)
+
+// Modes lists every Mode, in the order they're offered.
+var Modes = []Mode{AnagramMode, PalindromeMode, HiddenWordMode, SpoonerismMode, LadderMode, CrypticMode}
+
+func (m Mode) String() string {
+	switch m {
+	case PalindromeMode:
+		return "Palindromes"
+	case HiddenWordMode:
+		return "Hidden words"
+	case SpoonerismMode:
+		return "Spoonerisms"
+	case LadderMode:
+		return "Word ladders"
+	case CrypticMode:
+		return "Cryptic fodder"
+	default:
+		return "Anagrams"
+	}
+}
+
+// ordered reports whether the order of the words in a result matters, so
+// results with the same words in a different order are different.
+func (m Mode) ordered() bool {
+	return m == PalindromeMode || m == SpoonerismMode || m == LadderMode
+}
+
+// min_hidden_letters is the shortest hidden word worth showing. Almost any
+// run of one or two letters makes a word.
+const min_hidden_letters = 3
+
+// FindWordplay streams the results of a search in the given mode. Only
+// AnagramMode and CrypticMode use include or spread the search across
+// workers.
+func FindWordplay(ctx context.Context, mode Mode, input string, include []string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	switch mode {
+	case PalindromeMode:
+		return FindPalindromes(ctx, input, dictionary, opts)
+	case HiddenWordMode:
+		return FindHiddenWords(ctx, input, dictionary, opts)
+	case SpoonerismMode:
+		return FindSpoonerisms(ctx, input, dictionary, opts)
+	case LadderMode:
+		return FindWordLadders(ctx, input, dictionary, opts)
+	case CrypticMode:
+		return FindFodder(ctx, input, include, dictionary, opts)
+	default:
+		return FindAnagramsWithOptions(ctx, input, include, dictionary, opts)
+	}
+}
+
+// FindPalindromes streams the anagrams of input whose letters, ignoring
+// the spaces, read the same backwards, such as "step on no pets". Phrases
+// are built from both ends at once, so only words that mirror the other end
+// are tried. MinWords, MaxWords and Leftover apply as they do to anagrams;
+// MaxLeftover doesn't.
+func FindPalindromes(ctx context.Context, input string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	return startWordplay(ctx, input, dictionary, opts, func(s *searcher, output chan<- string) {
+		s.findPalindromes(input, dictionary, output)
+	})
+}
+
+// FindHiddenWords streams the words whose letters are those of some run of
+// the input's letters, in any order, longest first. The run can cross the
+// spaces between the input's words, so "karma manager" hides "arm", "mama"
+// and "range". The input's own words aren't included, nor any word shorter
+// than min_hidden_letters.
+func FindHiddenWords(ctx context.Context, input string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	return startWordplay(ctx, input, dictionary, opts, func(s *searcher, output chan<- string) {
+		s.findHiddenWords(input, dictionary, output)
+	})
+}
+
+// FindSpoonerisms streams the input with the leading consonants of two of
+// its words swapped, for each pair where both new words are in the
+// dictionary, so "belly jeans" gives "jelly beans".
+func FindSpoonerisms(ctx context.Context, input string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	return startWordplay(ctx, input, dictionary, opts, func(s *searcher, output chan<- string) {
+		s.findSpoonerisms(input, dictionary, output)
+	})
+}
+
+// FindWordLadders streams every shortest ladder from the first of input's
+// two words to the second, such as "cold cord card ward warm". Each step
+// changes one letter and makes a dictionary word. The two ends don't have
+// to be in the dictionary. With MaxWords, longer ladders are left out.
+func FindWordLadders(ctx context.Context, input string, dictionary *Dictionary, opts SearchOptions) <-chan string {
+	return startWordplay(ctx, input, dictionary, opts, func(s *searcher, output chan<- string) {
+		s.findWordLadders(input, dictionary, output)
+	})
+}
+
+// startWordplay runs search on its own goroutine, closing the channel it
+// returns once the search is done.
+func startWordplay(ctx context.Context, input string, dictionary *Dictionary, opts SearchOptions, search func(s *searcher, output chan<- string)) <-chan string {
+	outputChan := make(chan string, 10)
+
+	s := newSearcher(ctx, dictionary, opts)
+	go func() {
+		defer func() {
+			if ctx.Err() == nil {
+				opts.Progress.finish()
+			}
+			close(outputChan)
+		}()
+
+		if strings.TrimSpace(input) != "" {
+			search(s, outputChan)
+		}
+	}()
+
+	return outputChan
+}
+
+// send passes result on unless the search has been cancelled.
+func (s *searcher) send(result string, output chan<- string) {
+	select {
+	case output <- result:
+	case <-s.ctx.Done():
+	}
+}
+
+func reverseString(s string) string {
+	r := []rune(s)
+	slices.Reverse(r)
+	return string(r)
+}
+
+func isPalindrome(s string) bool {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		if r[i] != r[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// palindromeSearch builds a phrase from both ends. The letters on one side
+// always start the mirror image of those on the other, and whichever side
+// is longer has the overhang still to be matched. Words are added to the
+// shorter side, or the left when they're level, so each phrase is built in
+// only one way.
+type palindromeSearch struct {
+	*searcher
+	texts []string // the letters of each of s.words
+	left  []int    // words from the start of the phrase
+	right []int    // words from the end of the phrase, last word first
+}
+
+func (s *searcher) findPalindromes(input string, dictionary *Dictionary, output chan<- string) {
+	filtered, target, ok := s.prepare(input, dictionary)
+	if !ok {
+		return
+	}
+	odd := 0
+	for _, n := range target {
+		odd += n % 2
+	}
+	if odd > 1 {
+		return // only the middle letter can be unpaired
+	}
+
+	p := &palindromeSearch{searcher: s, texts: make([]string, len(filtered))}
+	words := make([]int, len(filtered))
+	for i := range filtered {
+		p.texts[i] = s.alphabet.letterString(filtered[i].Word)
+		words[i] = i
+	}
+	p.search(target, words, "", false, output)
+}
+
+// search extends the phrase with words from dict that fit in target. The
+// overhang is on the left if leftLonger is set.
+func (p *palindromeSearch) search(target *RuneCluster, dict []int, overhang string, leftLonger bool, output chan<- string) {
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	c := p.opts.Constraints
+	words := len(p.left) + len(p.right)
+	if target.IsEmpty() {
+		// Whatever's left over sits in the middle, so has to mirror itself.
+		if words > 0 && c.allowsCount(words) && isPalindrome(overhang) {
+			p.send(p.phrase(), output)
+		}
+		return
+	}
+	if c.MaxWords > 0 && words >= c.MaxWords {
+		return
+	}
+
+	var fits []int
+	for _, i := range dict {
+		if p.words[i].cluster.SubSetOf(target) {
+			fits = append(fits, i)
+		}
+	}
+
+	for _, i := range fits {
+		piece := p.texts[i]
+		if leftLonger {
+			piece = reverseString(piece) // read from the end
+		}
+
+		// Either the overhang gets shorter, or the piece passes it and
+		// becomes the overhang on the other side.
+		var newOverhang string
+		newLeftLonger := leftLonger
+		if strings.HasPrefix(overhang, piece) {
+			newOverhang = overhang[len(piece):]
+		} else if strings.HasPrefix(piece, overhang) {
+			newOverhang = piece[len(overhang):]
+			newLeftLonger = !leftLonger
+		} else {
+			continue
+		}
+
+		remaining, _ := target.Minus(p.words[i].cluster)
+		if leftLonger {
+			p.right = append(p.right, i)
+		} else {
+			p.left = append(p.left, i)
+		}
+		p.search(remaining, fits, newOverhang, newLeftLonger && newOverhang != "", output)
+		if leftLonger {
+			p.right = p.right[:len(p.right)-1]
+		} else {
+			p.left = p.left[:len(p.left)-1]
+		}
+	}
+}
+
+func (p *palindromeSearch) phrase() string {
+	words := make([]string, 0, len(p.left)+len(p.right))
+	for _, i := range p.left {
+		words = append(words, p.words[i].Word)
+	}
+	for j := len(p.right) - 1; j >= 0; j-- {
+		words = append(words, p.words[p.right[j]].Word)
+	}
+	return strings.Join(words, " ")
+}
+
+func (s *searcher) findHiddenWords(input string, dictionary *Dictionary, output chan<- string) {
+	filtered, _ := FilterAnnotatedDict(input, dictionary)
+	filtered = filtered.FilterWords(s.opts.Constraints)
+	if len(filtered) == 0 {
+		return
+	}
+	index := newSignatureIndex(filtered)
+
+	letters := []rune(s.alphabet.letterString(input))
+	clusters := make([]*RuneCluster, len(letters))
+	for i, r := range letters {
+		clusters[i] = s.alphabet.Cluster(string(r))
+	}
+	inputWords := make(map[string]bool)
+	for _, word := range strings.Fields(UnmarkSpaces(input)) {
+		inputWords[s.alphabet.letterString(word)] = true
+	}
+
+	shortest, longest := filtered.letterRange()
+	shortest = max(shortest, min_hidden_letters)
+	longest = min(longest, len(letters))
+	seen := make(map[string]bool)
+	for length := longest; length >= shortest; length-- {
+		for start := 0; start+length <= len(letters); start++ {
+			if s.ctx.Err() != nil {
+				return
+			}
+			var window RuneCluster
+			for _, rc := range clusters[start : start+length] {
+				window.Add(rc)
+			}
+			index.lookup(&window, func(dp *dictPair) {
+				if !seen[dp.Word] && !inputWords[s.alphabet.letterString(dp.Word)] {
+					seen[dp.Word] = true
+					s.send(dp.Word, output)
+				}
+			})
+		}
+	}
+}
+
+func (s *searcher) findSpoonerisms(input string, dictionary *Dictionary, output chan<- string) {
+	words := strings.Fields(UnmarkSpaces(input))
+	if len(words) < 2 {
+		log.Println("Spoonerisms need at least two words")
+		return
+	}
+
+	// Dictionary words by their letters, the first spelling winning.
+	known := make(map[string]string)
+	for _, dp := range GetAnnotatedDict(dictionary).FilterWords(s.opts.Constraints) {
+		key := s.alphabet.letterString(dp.Word)
+		if _, ok := known[key]; !ok {
+			known[key] = dp.Word
+		}
+	}
+
+	for i := range words {
+		for j := i + 1; j < len(words); j++ {
+			if s.ctx.Err() != nil {
+				return
+			}
+			onsetA, restA := splitOnset(s.alphabet.letterString(words[i]))
+			onsetB, restB := splitOnset(s.alphabet.letterString(words[j]))
+			if onsetA == onsetB || restA == "" || restB == "" {
+				continue
+			}
+			newA, okA := known[onsetB+restA]
+			newB, okB := known[onsetA+restB]
+			if okA && okB {
+				phrase := slices.Clone(words)
+				phrase[i], phrase[j] = newA, newB
+				s.send(strings.Join(phrase, " "), output)
+			}
+		}
+	}
+}
+
+// splitOnset splits the letters of a word into the consonants before its
+// first vowel and the rest. A "u" after a "q" goes with the consonants, and
+// a "y" after the first letter is a vowel.
+func splitOnset(letters string) (string, string) {
+	r := []rune(letters)
+	i := 0
+	for i < len(r) && !isVowel(r[i]) && !(r[i] == 'y' && i > 0) {
+		i++
+	}
+	if i > 0 && i < len(r) && r[i-1] == 'q' && r[i] == 'u' {
+		i++
+	}
+	return string(r[:i]), string(r[i:])
+}
+
+// isVowel reports whether r is a vowel, accented or not.
+func isVowel(r rune) bool {
+	if f, ok := latinFolds[r]; ok {
+		r = []rune(f)[0]
+	}
+	return strings.ContainsRune("aeiou", r)
+}
+
+func (s *searcher) findWordLadders(input string, dictionary *Dictionary, output chan<- string) {
+	ends := strings.Fields(UnmarkSpaces(input))
+	if len(ends) != 2 {
+		log.Println("Word ladders need two words")
+		return
+	}
+	from, to := s.alphabet.letterString(ends[0]), s.alphabet.letterString(ends[1])
+	length := len([]rune(from))
+	if length != len([]rune(to)) || from == to {
+		return
+	}
+
+	// The rungs are the dictionary words with as many letters, by their
+	// letters, the first spelling winning. The ends keep the input's.
+	names := make(map[string]string)
+	for _, dp := range GetAnnotatedDict(dictionary).FilterWords(s.opts.Constraints) {
+		if dp.letters != length {
+			continue
+		}
+		key := s.alphabet.letterString(dp.Word)
+		if _, ok := names[key]; !ok {
+			names[key] = dp.Word
+		}
+	}
+	names[from], names[to] = ends[0], ends[1]
+
+	// Words one letter apart share a pattern with that letter blanked out.
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	patterns := make(map[string][]string)
+	eachPattern := func(key string, found func(pattern string)) {
+		r := []rune(key)
+		for i, c := range r {
+			r[i] = '*'
+			found(string(r))
+			r[i] = c
+		}
+	}
+	for _, key := range keys {
+		eachPattern(key, func(pattern string) {
+			patterns[pattern] = append(patterns[pattern], key)
+		})
+	}
+
+	// A breadth first search from the start finds each word's parents one
+	// step closer, until a layer reaches the end.
+	maxWords := s.opts.Constraints.MaxWords
+	parents := map[string][]string{from: nil}
+	layer := []string{from}
+	for steps := 1; len(layer) > 0 && parents[to] == nil; steps++ {
+		if s.ctx.Err() != nil || (maxWords > 0 && steps >= maxWords) {
+			return
+		}
+		var next []string
+		reached := make(map[string]bool)
+		for _, word := range layer {
+			eachPattern(word, func(pattern string) {
+				for _, other := range patterns[pattern] {
+					if _, old := parents[other]; old && !reached[other] {
+						continue
+					}
+					if !reached[other] {
+						reached[other] = true
+						next = append(next, other)
+					}
+					parents[other] = append(parents[other], word)
+				}
+			})
+		}
+		layer = next
+	}
+	if parents[to] == nil {
+		return
+	}
+
+	// Every way back from the end along the parents is a shortest ladder.
+	ladder := []string{to}
+	var climb func(word string)
+	climb = func(word string) {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if word == from {
+			rungs := make([]string, len(ladder))
+			for i, key := range ladder {
+				rungs[len(ladder)-1-i] = names[key]
+			}
+			s.send(strings.Join(rungs, " "), output)
+			return
+		}
+		for _, parent := range parents[word] {
+			ladder = append(ladder, parent)
+			climb(parent)
+			ladder = ladder[:len(ladder)-1]
+		}
+	}
+	climb(to)
+}
@@ -0,0 +1,180 @@
+package anagram
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// wordplayDict has words for every mode.
+var wordplayDict = &Dictionary{
+	Name: "wordplay",
+	Words: []string{
+		"step", "on", "no", "pets", "noon", "pots", "stop", "spot", "top",
+		"cold", "cord", "card", "ward", "warm", "word", "worm", "wold",
+		"belly", "jeans", "jelly", "beans", "quick", "sick", "quit", "sit",
+		"arm", "mama", "range", "anger", "man", "age",
+	},
+}
+
+func findWordplay(mode Mode, input string, c Constraints) []string {
+	var results []string
+	for result := range FindWordplay(context.Background(), mode, input, nil, wordplayDict, SearchOptions{Constraints: c}) {
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestFindPalindromes(t *testing.T) {
+	results := findWordplay(PalindromeMode, "pets on no step", Constraints{})
+	for _, result := range results {
+		if !isPalindrome(LatinAlphabet.letterString(result)) {
+			t.Errorf("%q isn't a palindrome", result)
+		}
+		if left := LatinAlphabet.Leftover("pets on no step", result); left == nil || !left.IsEmpty() {
+			t.Errorf("%q isn't an anagram of the input", result)
+		}
+	}
+	for _, want := range []string{"step on no pets", "pets on no step", "step noon pets", "on step pets no"} {
+		if !slices.Contains(results, want) {
+			t.Errorf("Expected %q in %v", want, results)
+		}
+	}
+	if len(results) != len(uniqueStrings(results)) {
+		t.Errorf("Palindromes repeated in %v", results)
+	}
+
+	for _, result := range findWordplay(PalindromeMode, "pets on no step", Constraints{MaxWords: 3}) {
+		if result != "step noon pets" && result != "pets noon step" {
+			t.Errorf("Expected three word palindromes, got %q", result)
+		}
+	}
+
+	if results := findWordplay(PalindromeMode, "cold warm", Constraints{}); len(results) != 0 {
+		t.Errorf("Expected no palindromes with so many unpaired letters, got %v", results)
+	}
+}
+
+func TestFindHiddenWords(t *testing.T) {
+	results := findWordplay(HiddenWordMode, "karma manager", Constraints{})
+	want := []string{"range", "anger", "mama", "arm", "man", "age"}
+	if !slices.Equal(results, want) {
+		t.Errorf("Expected %v hidden in karma manager, got %v", want, results)
+	}
+
+	results = findWordplay(HiddenWordMode, "karma manager", Constraints{MaxWordLength: 4})
+	if slices.Contains(results, "range") || !slices.Contains(results, "mama") {
+		t.Errorf("Expected words of no more than four letters, got %v", results)
+	}
+}
+
+func TestFindSpoonerisms(t *testing.T) {
+	if results := findWordplay(SpoonerismMode, "belly jeans", Constraints{}); !slices.Equal(results, []string{"jelly beans"}) {
+		t.Errorf("Expected jelly beans, got %v", results)
+	}
+	// "qu" stays together.
+	if results := findWordplay(SpoonerismMode, "quick sit", Constraints{}); !slices.Equal(results, []string{"sick quit"}) {
+		t.Errorf("Expected sick quit, got %v", results)
+	}
+	if results := findWordplay(SpoonerismMode, "belly", Constraints{}); len(results) != 0 {
+		t.Errorf("Expected no spoonerisms of one word, got %v", results)
+	}
+}
+
+func TestSplitOnset(t *testing.T) {
+	cases := []struct{ word, onset, rest string }{
+		{"belly", "b", "elly"},
+		{"string", "str", "ing"},
+		{"apple", "", "apple"},
+		{"queen", "qu", "een"},
+		{"rhythm", "rh", "ythm"},
+		{"yes", "y", "es"},
+	}
+	for _, c := range cases {
+		if onset, rest := splitOnset(c.word); onset != c.onset || rest != c.rest {
+			t.Errorf("Split %q into %q and %q, expected %q and %q", c.word, onset, rest, c.onset, c.rest)
+		}
+	}
+}
+
+func TestFindWordLadders(t *testing.T) {
+	results := findWordplay(LadderMode, "cold warm", Constraints{})
+	want := []string{
+		"cold cord card ward warm",
+		"cold cord word ward warm",
+		"cold cord word worm warm",
+		"cold wold word ward warm",
+		"cold wold word worm warm",
+	}
+	slices.Sort(results)
+	if !slices.Equal(results, want) {
+		t.Errorf("Expected ladders %v, got %v", want, results)
+	}
+
+	if results := findWordplay(LadderMode, "cold warm", Constraints{MaxWords: 4}); len(results) != 0 {
+		t.Errorf("Expected no ladders of four words, got %v", results)
+	}
+	if results := findWordplay(LadderMode, "cold belly", Constraints{}); len(results) != 0 {
+		t.Errorf("Expected no ladders between words of different lengths, got %v", results)
+	}
+}
+
+func TestResultSetModes(t *testing.T) {
+	rs := newTestResultSet()
+	rs.FindAnagrams("star eats crate")
+	rs.FetchTo(1000000)
+	anagrams := rs.Count()
+
+	rs.SetMode(HiddenWordMode)
+	rs.FetchTo(1000000)
+	for i := 0; i < rs.Count(); i++ {
+		result, _ := rs.GetAt(i)
+		if left := rs.LeftoverAt(i); left != "" {
+			t.Errorf("Hidden word %q has leftover %q", result, left)
+		}
+	}
+	if rs.Count() == 0 || rs.Count() == anagrams {
+		t.Errorf("Expected the hidden words, got %d results", rs.Count())
+	}
+	if total, err := rs.TotalCount(context.Background()); err != nil || total != rs.Count() {
+		t.Errorf("Expected all %d hidden words counted, got %d and %v", rs.Count(), total, err)
+	}
+	rs.SetOrder(RankedOrder)
+	if _, err := rs.TotalCount(context.Background()); err != ErrOnlyAnagrams {
+		t.Errorf("Expected ErrOnlyAnagrams counting ranked hidden words, got %v", err)
+	}
+	rs.SetOrder(DictionaryOrder)
+
+	rs.SetMode(AnagramMode)
+	if rs.Count() != anagrams {
+		t.Errorf("Expected the anagrams back, got %d results", rs.Count())
+	}
+
+	// Switching modes and regenerating while another goroutine fetches
+	// mustn't race with it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			rs.FetchTo(anagrams)
+			rs.GetAt(anagrams - 1)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		rs.SetMode(HiddenWordMode)
+		rs.Regenerate()
+		rs.SetMode(AnagramMode)
+	}
+	<-done
+	rs.FetchTo(1000000)
+	if rs.Count() != anagrams {
+		t.Errorf("Expected %d anagrams after switching modes, got %d", anagrams, rs.Count())
+	}
+	rs.Abort()
+}
+
+func uniqueStrings(list []string) []string {
+	unique := slices.Clone(list)
+	slices.Sort(unique)
+	return slices.Compact(unique)
+}
@@ -20,6 +20,8 @@ import (
 	"fyne.io/fyne/v2/driver/software"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 var glyphSize fyne.Size = fyne.NewSize(15, 20)
@@ -70,19 +72,19 @@ func layoutCenterOffset(layout []RuneLayoutElement, dispSize fyne.Size) fyne.Pos
 	)
 }
 
-func NewAnimation(input string, anagrams []string, dispSize fyne.Size) (*Animation, error) {
+func NewAnimation(input string, anagrams []string, alphabet *anagram.Alphabet, dispSize fyne.Size) (*Animation, error) {
 	maxCols := int(math.Floor(float64(dispSize.Width / (glyphSize.Width + glyphSpacing))))
 
-	inputRC := NewRuneCluster(input)
-	for _, anagram := range anagrams {
-		anagramRC := NewRuneCluster(anagram)
+	inputRC := alphabet.Cluster(input)
+	for _, phrase := range anagrams {
+		anagramRC := alphabet.Cluster(phrase)
 		if !inputRC.Equals(anagramRC) {
 			return nil, errors.New("input doesn't match anagram")
 		}
 	}
 
 	inputLC := strings.ToLower(input)
-	inputLayout, rows := MakeRuneLayout(inputLC, maxCols)
+	inputLayout, rows := MakeRuneLayout(inputLC, alphabet, maxCols)
 	numGlyphs := len(inputLayout)
 	glyphs := make([]RuneGlyph, 0, numGlyphs)
 
@@ -96,9 +98,9 @@ func NewAnimation(input string, anagrams []string, dispSize fyne.Size) (*Animati
 	}
 
 	offscreenParking := fyne.NewPos(-2*glyphSize.Width, -2*glyphSize.Height)
-	for index, anagram := range anagrams {
-		anagramLC := strings.ToLower(anagram)
-		anagramLayout, anagramRows := MakeRuneLayout(anagramLC, maxCols)
+	for index, phrase := range anagrams {
+		anagramLC := strings.ToLower(phrase)
+		anagramLayout, anagramRows := MakeRuneLayout(anagramLC, alphabet, maxCols)
 		if anagramRows > rows {
 			rows = anagramRows
 		}
@@ -155,14 +157,17 @@ type AnimationDisplay struct {
 	badgeLabel       *widget.Label
 	pendingInput     string
 	pendingAnagrams  []string
+	alphabet         *anagram.Alphabet
 }
 
-func NewAnimationDisplay(icon fyne.Resource) *AnimationDisplay {
+// NewAnimationDisplay makes a display that animates anagrams, matching up
+// their letters the way alphabet counts them.
+func NewAnimationDisplay(icon fyne.Resource, alphabet *anagram.Alphabet) *AnimationDisplay {
 	surface := container.NewWithoutLayout()
 	scroll := container.NewScroll(surface)
 	scroll.Direction = container.ScrollNone
 
-	ad := &AnimationDisplay{surface: surface, scroll: scroll, Icon: icon, Badge: "made with KarmaManager"}
+	ad := &AnimationDisplay{surface: surface, scroll: scroll, Icon: icon, Badge: "made with KarmaManager", alphabet: alphabet}
 	ad.ExtendBaseWidget(ad)
 	return ad
 }
@@ -233,7 +238,7 @@ func (ad *AnimationDisplay) AnimateAnagrams(input string, anagrams ...string) {
 func (ad *AnimationDisplay) startAnimation(input string, anagrams []string, dispSize fyne.Size) {
 	style := fyne.TextStyle{Monospace: true}
 
-	animation, err := NewAnimation(input, anagrams, dispSize)
+	animation, err := NewAnimation(input, anagrams, ad.alphabet, dispSize)
 	if err != nil {
 		log.Println(err)
 		ad.running = false
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+var batchLimits = []int{5, 10, 20, 50, 100}
+
+var batchTimeLimits = []time.Duration{5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute, 0}
+
+// ShowBatchDialog picks a file of inputs, one per line or CSV, and finds
+// anagrams of each with the Find tab's dictionaries and limits, then saves
+// a report of them all.
+func ShowBatchDialog(rs *anagram.ResultSet, window fyne.Window) {
+	fd := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+		if err != nil || uc == nil {
+			return
+		}
+		defer uc.Close()
+		isCSV := strings.ToLower(uc.URI().Extension()) == ".csv"
+		inputs, err := anagram.ReadBatchInputs(uc, isCSV)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if len(inputs) == 0 {
+			dialog.ShowError(errors.New("There's nothing to anagram in "+uc.URI().Name()), window)
+			return
+		}
+		showBatchOptions(rs, inputs, window)
+	}, window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".txt", ".csv"}))
+	fd.Show()
+}
+
+// showBatchOptions asks how many anagrams to find for each input, how long
+// to spend on each and whether to use the Find tab's word pattern, then runs
+// the batch.
+func showBatchOptions(rs *anagram.ResultSet, inputs []string, window fyne.Window) {
+	limitChoices := make([]string, len(batchLimits))
+	for i, limit := range batchLimits {
+		limitChoices[i] = fmt.Sprintf("%d", limit)
+	}
+	limitSelect := widget.NewSelect(limitChoices, nil)
+	limitSelect.SetSelectedIndex(1)
+
+	timeChoices := make([]string, len(batchTimeLimits))
+	for i, limit := range batchTimeLimits {
+		if limit == 0 {
+			timeChoices[i] = "No limit"
+		} else {
+			timeChoices[i] = limit.String()
+		}
+	}
+	timeSelect := widget.NewSelect(timeChoices, nil)
+	timeSelect.SetSelectedIndex(1)
+
+	bestFirst := widget.NewCheck("", nil)
+	bestFirst.SetChecked(true)
+
+	// The Find tab's letters to leave over belong to its input, so they're
+	// never used, but its word pattern can be if it suits every input.
+	constraints := rs.Constraints()
+	constraints.Leftover = ""
+	usePattern := widget.NewCheck(constraints.Pattern, nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Anagrams of each", limitSelect),
+		widget.NewFormItem("Time for each", timeSelect),
+		widget.NewFormItem("Best first", bestFirst),
+	}
+	if constraints.Pattern != "" {
+		items = append(items, widget.NewFormItem("Use word pattern", usePattern))
+	}
+	title := fmt.Sprintf("Anagram %d inputs", len(inputs))
+	if len(inputs) == 1 {
+		title = "Anagram 1 input"
+	}
+	d := dialog.NewForm(title, "Run", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+		opts := anagram.BatchOptions{
+			Search:    anagram.DefaultSearchOptions,
+			Limit:     batchLimits[limitSelect.SelectedIndex()],
+			TimeLimit: batchTimeLimits[timeSelect.SelectedIndex()],
+		}
+		if !usePattern.Checked {
+			constraints.Pattern = ""
+		}
+		opts.Search.Constraints = constraints
+		if bestFirst.Checked {
+			opts.Scorer = rs.Scorer()
+		}
+		runBatch(rs.CombinedDict(), inputs, opts, window)
+	}, window)
+	d.Resize(fyne.NewSize(300, 250))
+	d.Show()
+}
+
+// runBatch runs the batch with a progress bar that can stop it, then offers
+// to save the report.
+func runBatch(dict *anagram.Dictionary, inputs []string, opts anagram.BatchOptions, window fyne.Window) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progressBar := widget.NewProgressBar()
+	progressDialog := dialog.NewCustom(fmt.Sprintf("Anagramming %d inputs…", len(inputs)), "Stop", progressBar, window)
+	progressDialog.SetOnClosed(cancel)
+	progressDialog.Resize(fyne.NewSize(300, 100))
+	progressDialog.Show()
+
+	go func() {
+		results, err := anagram.RunBatch(ctx, inputs, dict, opts, func(done, total int) {
+			fyne.Do(func() {
+				progressBar.SetValue(float64(done) / float64(total))
+			})
+		})
+		fyne.Do(func() {
+			progressDialog.Hide()
+			if err != nil && len(results) == 0 {
+				return // stopped before anything was found
+			}
+			saveBatchReport(results, window)
+		})
+	}()
+}
+
+// saveBatchReport saves the report as text, CSV or JSON, going by the file
+// name's extension.
+func saveBatchReport(results []anagram.BatchResult, window fyne.Window) {
+	fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+		if err != nil || uc == nil {
+			return
+		}
+		defer uc.Close()
+		format := "text"
+		switch strings.ToLower(uc.URI().Extension()) {
+		case ".csv":
+			format = "csv"
+		case ".json":
+			format = "json"
+		}
+		if err := anagram.WriteBatchReport(uc, results, format); err != nil {
+			dialog.ShowError(err, window)
+		}
+	}, window)
+	fd.SetFileName("anagrams.txt")
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".txt", ".csv", ".json"}))
+	fd.Show()
+}
@@ -0,0 +1,260 @@
+// Command karma finds anagrams from the command line, using the same engine
+// and dictionaries as the Karma Manager app. It needs no display, so it can
+// be used in scripts:
+//
+//	karma -dict UK -add names -limit 20 -format csv Karma Manager
+//
+// With -batch it reads a file of inputs instead, one per line or CSV, and
+// writes a report with the anagrams of each:
+//
+//	karma -batch attendees.csv -best -limit 5 -timeout 10s
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run does the work of main, returning the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("karma", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: karma [flags] phrase...")
+		flags.PrintDefaults()
+	}
+
+	mainName := flags.String("dict", "", "main dictionary, by description or file name (default the first one)")
+	addedNames := flags.String("add", "", "comma separated added dictionaries, or \"none\" (default the ones the app enables)")
+	include := flags.String("include", "", "comma separated phrases every result must contain")
+	exclude := flags.String("exclude", "", "comma separated words to leave out")
+	limit := flags.Int("limit", 0, "stop after this many results, 0 for no limit; with -batch, the anagrams kept for each input, 10 if 0")
+	format := flags.String("format", "text", "output format: text, json or csv")
+	batch := flags.String("batch", "", "file of inputs, one per line or CSV, to anagram in turn instead of a phrase")
+	best := flags.Bool("best", false, "with -batch, keep the best anagrams of each input rather than the first found")
+	timeout := flags.Duration("timeout", 0, "with -batch, the longest to search each input for, 0 for no limit")
+	list := flags.Bool("list", false, "list the dictionaries and exit")
+	verbose := flags.Bool("v", false, "log search progress to stderr")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *verbose {
+		log.SetOutput(stderr)
+	} else {
+		log.SetOutput(io.Discard)
+	}
+
+	mainConfigs, addedConfigs, err := anagram.ReadConfigs()
+	if err != nil {
+		fmt.Fprintln(stderr, "Can't read dictionary configuration:", err)
+		return 1
+	}
+
+	if *list {
+		fmt.Fprintln(stdout, "Main dictionaries:")
+		for _, mdc := range mainConfigs {
+			fmt.Fprintf(stdout, "  %-24s %s\n", mdc.Description, mdc.File)
+		}
+		fmt.Fprintln(stdout, "Added dictionaries:")
+		for _, adc := range addedConfigs {
+			fmt.Fprintf(stdout, "  %-24s %s\n", adc.Description, adc.File)
+		}
+		return 0
+	}
+
+	if *batch != "" {
+		return runBatch(*batch, mainConfigs, addedConfigs, *mainName, *addedNames, *include, *exclude, *format, *limit, *timeout, *best, stdout, stderr)
+	}
+
+	input := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(input) == "" {
+		flags.Usage()
+		return 2
+	}
+
+	newWriter, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "Unknown format %q\n", *format)
+		return 2
+	}
+
+	dict, _, err := loadDictionary(mainConfigs, addedConfigs, *mainName, *addedNames, splitList(*exclude))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	out := newWriter(stdout)
+	count := 0
+	seen := anagram.NewDeduper(input, dict.Alphabet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for result := range anagram.FindAnagramsContext(ctx, input, splitList(*include), dict) {
+		if !seen.IsNew(result) {
+			continue
+		}
+
+		if err := out.Write(input, anagram.UnmarkSpaces(result)); err != nil {
+			fmt.Fprintln(stderr, "Can't write result:", err)
+			return 1
+		}
+		count += 1
+		if *limit > 0 && count >= *limit {
+			break
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(stderr, "Can't write results:", err)
+		return 1
+	}
+	return 0
+}
+
+// runBatch anagrams every input in the file, writing a report in the given
+// format, and returns the exit status.
+func runBatch(file string, mainConfigs []anagram.MainDictionaryConfig, addedConfigs []anagram.AddedDictionaryConfig, mainName, addedNames, include, exclude, format string, limit int, timeout time.Duration, best bool, stdout, stderr io.Writer) int {
+	if !slices.Contains(anagram.BatchFormats, format) {
+		fmt.Fprintf(stderr, "Unknown format %q\n", format)
+		return 2
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintln(stderr, "Can't read inputs:", err)
+		return 1
+	}
+	inputs, err := anagram.ReadBatchInputs(f, strings.EqualFold(filepath.Ext(file), ".csv"))
+	f.Close()
+	if err != nil {
+		fmt.Fprintln(stderr, "Can't read inputs:", err)
+		return 1
+	}
+
+	dict, added, err := loadDictionary(mainConfigs, addedConfigs, mainName, addedNames, splitList(exclude))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	opts := anagram.BatchOptions{
+		Search:    anagram.DefaultSearchOptions,
+		Include:   splitList(include),
+		Limit:     limit,
+		TimeLimit: timeout,
+	}
+	if best {
+		// Ranked the way the app ranks its best first results.
+		opts.Scorer = anagram.NewScorer()
+		opts.Scorer.Frequency = anagram.DictionaryFrequency(dict)
+		for _, d := range added {
+			if d.Bonus != 0 {
+				opts.Scorer.AddBonus(d, d.Bonus)
+			}
+		}
+	}
+
+	results, err := anagram.RunBatch(context.Background(), inputs, dict, opts, func(done, total int) {
+		log.Printf("Finished %d of %d inputs\n", done, total)
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "Batch failed:", err)
+		return 1
+	}
+	if err := anagram.WriteBatchReport(stdout, results, format); err != nil {
+		fmt.Fprintln(stderr, "Can't write report:", err)
+		return 1
+	}
+	return 0
+}
+
+// splitList splits a comma separated flag into phrases, marking the spaces
+// inside each one so it's treated as a single word.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) != "" {
+			items = append(items, anagram.MarkSpaces(item))
+		}
+	}
+	return items
+}
+
+// matches reports whether name picks the dictionary with this description
+// and file. Case doesn't matter, and the first word of the description or
+// the file name without .json is enough, e.g. "uk" or "en_GB-ise".
+func matches(name, description, file string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	description = strings.ToLower(description)
+	file = strings.ToLower(file)
+	if name == description || name == file || name == strings.TrimSuffix(file, ".json") {
+		return true
+	}
+	words := strings.Fields(description)
+	return len(words) > 0 && name == words[0]
+}
+
+// loadDictionary combines the chosen main dictionary with the chosen added
+// ones, leaving out the excluded words. The result keeps the main
+// dictionary's alphabet. It also returns the added dictionaries it chose.
+func loadDictionary(mainConfigs []anagram.MainDictionaryConfig, addedConfigs []anagram.AddedDictionaryConfig, mainName, addedNames string, excluded []string) (*anagram.Dictionary, []*anagram.Dictionary, error) {
+	mainDicts, addedDicts, err := anagram.NewDictionaries(mainConfigs, addedConfigs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mainIndex := -1
+	if mainName == "" {
+		mainIndex = 0
+	}
+	for i, mdc := range mainConfigs {
+		if mainIndex < 0 && matches(mainName, mdc.Description, mdc.File) {
+			mainIndex = i
+		}
+	}
+	if mainIndex < 0 {
+		return nil, nil, errors.New("No main dictionary called \"" + mainName + "\", try -list")
+	}
+
+	dicts := []*anagram.Dictionary{mainDicts[mainIndex]}
+	if addedNames == "" {
+		for _, d := range addedDicts {
+			if d.Enabled {
+				dicts = append(dicts, d)
+			}
+		}
+	} else if strings.ToLower(addedNames) != "none" {
+		for _, name := range strings.Split(addedNames, ",") {
+			found := false
+			for i, adc := range addedConfigs {
+				if matches(name, adc.Description, adc.File) {
+					dicts = append(dicts, addedDicts[i])
+					found = true
+				}
+			}
+			if !found {
+				return nil, nil, errors.New("No added dictionary called \"" + strings.TrimSpace(name) + "\", try -list")
+			}
+		}
+	}
+
+	return anagram.MergeDictionaries(excluded, dicts...), dicts[1:], nil
+}
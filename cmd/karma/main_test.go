@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+func runKarma(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), status
+}
+
+func TestRunText(t *testing.T) {
+	out, errOut, status := runKarma(t, "-limit", "5", "Karma", "Manager")
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, errOut)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 results, got %d: %q", len(lines), out)
+	}
+	input := anagram.NewRuneCluster("Karma Manager")
+	for _, line := range lines {
+		if !anagram.NewRuneCluster(line).Equals(input) {
+			t.Errorf("%q isn't an anagram of the input", line)
+		}
+	}
+	if errOut != "" {
+		t.Errorf("Expected nothing on stderr without -v, got %q", errOut)
+	}
+}
+
+func TestRunIncludeExclude(t *testing.T) {
+	out, _, status := runKarma(t, "-dict", "en_US", "-add", "none", "-include", "manager", "-exclude", "arak", "-limit", "20", "-format", "json", "Karma", "Manager")
+	if status != 0 {
+		t.Fatal("Search failed")
+	}
+
+	var results []jsonResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Output isn't JSON: %v\n%s", err, out)
+	}
+	if len(results) == 0 {
+		t.Fatal("Expected results")
+	}
+	for _, r := range results {
+		if r.Input != "Karma Manager" {
+			t.Errorf("Wrong input %q", r.Input)
+		}
+		words := strings.Fields(strings.ToLower(r.Anagram))
+		if words[0] != "manager" {
+			t.Errorf("%q doesn't start with the included phrase", r.Anagram)
+		}
+		for _, w := range words {
+			if w == "arak" {
+				t.Errorf("%q contains an excluded word", r.Anagram)
+			}
+		}
+	}
+}
+
+func TestRunCSV(t *testing.T) {
+	out, _, status := runKarma(t, "-format", "csv", "-limit", "3", "dormitory")
+	if status != 0 {
+		t.Fatal("Search failed")
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[0][0] != "input" || records[0][1] != "anagram" {
+		t.Errorf("Expected a header and 3 rows, got %v", records)
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "inputs.csv")
+	if err := os.WriteFile(file, []byte("name,company\nKarma Manager,Acme\ndormitory,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut, status := runKarma(t, "-batch", file, "-best", "-limit", "3", "-timeout", "5s", "-format", "json")
+	if status != 0 {
+		t.Fatalf("Exit status %d: %s", status, errOut)
+	}
+	var results []anagram.BatchResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Output isn't JSON: %v\n%s", err, out)
+	}
+	if len(results) != 2 || results[0].Input != "Karma Manager" || results[1].Input != "dormitory" {
+		t.Fatalf("Expected a result for each input, got %+v", results)
+	}
+	for _, result := range results {
+		if len(result.Anagrams) != 3 || len(result.Scores) != 3 {
+			t.Errorf("Expected the best 3 anagrams of %q, got %v", result.Input, result.Anagrams)
+		}
+		input := anagram.NewRuneCluster(result.Input)
+		for _, a := range result.Anagrams {
+			if !anagram.NewRuneCluster(a).Equals(input) {
+				t.Errorf("%q isn't an anagram of %q", a, result.Input)
+			}
+		}
+	}
+
+	if _, errOut, status := runKarma(t, "-batch", filepath.Join(t.TempDir(), "missing.txt")); status != 1 || !strings.Contains(errOut, "Can't read inputs") {
+		t.Errorf("Expected an error for a missing file, got %d %q", status, errOut)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	for _, name := range []string{"UK dictionary", "uk", "en_GB-ise", "en_GB-ise.json"} {
+		if !matches(name, "UK dictionary", "en_GB-ise.json") {
+			t.Errorf("%q should pick the UK dictionary", name)
+		}
+	}
+	if matches("dictionary", "UK dictionary", "en_GB-ise.json") || matches("en", "UK dictionary", "en_GB-ise.json") {
+		t.Error("Partial names shouldn't match")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if _, _, status := runKarma(t); status != 2 {
+		t.Error("Expected usage error without input")
+	}
+	if _, _, status := runKarma(t, "-format", "xml", "foo"); status != 2 {
+		t.Error("Expected error for unknown format")
+	}
+	if _, errOut, status := runKarma(t, "-dict", "Klingon", "foo"); status != 1 || !strings.Contains(errOut, "Klingon") {
+		t.Errorf("Expected error for unknown dictionary, got %d %q", status, errOut)
+	}
+	if out, _, status := runKarma(t, "-list"); status != 0 || !strings.Contains(out, "places.json") {
+		t.Errorf("Listing dictionaries failed: %q", out)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// resultWriter formats results as they're found. Close must be called to
+// finish the output.
+type resultWriter interface {
+	Write(input, anagram string) error
+	Close() error
+}
+
+var writers = map[string]func(io.Writer) resultWriter{
+	"text": newTextWriter,
+	"json": newJSONWriter,
+	"csv":  newCSVWriter,
+}
+
+// textWriter writes one anagram per line.
+type textWriter struct {
+	w *bufio.Writer
+}
+
+func newTextWriter(w io.Writer) resultWriter {
+	return &textWriter{bufio.NewWriter(w)}
+}
+
+func (tw *textWriter) Write(input, anagram string) error {
+	_, err := tw.w.WriteString(anagram + "\n")
+	return err
+}
+
+func (tw *textWriter) Close() error {
+	return tw.w.Flush()
+}
+
+// jsonResult is one element of the JSON output.
+type jsonResult struct {
+	Input   string `json:"input"`
+	Anagram string `json:"anagram"`
+}
+
+// jsonWriter writes a JSON array of jsonResults, one per line, without
+// holding them all in memory.
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer) resultWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (jw *jsonWriter) Write(input, anagram string) error {
+	data, err := json.Marshal(jsonResult{input, anagram})
+	if err != nil {
+		return err
+	}
+	if jw.count == 0 {
+		jw.w.WriteString("[\n  ")
+	} else {
+		jw.w.WriteString(",\n  ")
+	}
+	jw.count += 1
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.count == 0 {
+		jw.w.WriteString("[]\n")
+	} else {
+		jw.w.WriteString("\n]\n")
+	}
+	return jw.w.Flush()
+}
+
+// csvWriter writes an input,anagram header followed by a row per result.
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVWriter(w io.Writer) resultWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) Write(input, anagram string) error {
+	if !cw.header {
+		cw.header = true
+		if err := cw.w.Write([]string{"input", "anagram"}); err != nil {
+			return err
+		}
+	}
+	return cw.w.Write([]string{input, anagram})
+}
+
+func (cw *csvWriter) Close() error {
+	if !cw.header {
+		cw.w.Write([]string{"input", "anagram"})
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -1,173 +1,24 @@
 package main
 
 import (
-	"embed"
-	"encoding/json"
-	// "log"
-	"strings"
-
 	"fyne.io/fyne/v2"
-)
-
-//go:embed json
-var jsonFS embed.FS
-
-const nonSeperable = false
-
-type Dictionary struct {
-	Name      string
-	Words     []string
-	Enabled   bool
-	annotated annotatedDict // cached RuneClusters, built once
-}
 
-type MainDictionaryConfig struct {
-	Description string
-	File        string
-}
-
-type AddedDictionaryConfig struct {
-	Description string
-	File        string
-	Enabled     bool
-}
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
 
 const (
-	main_dicts_file         = "main-dicts.json"
-	added_dicts_file        = "added-dicts.json"
 	privateDictionaryKey    = "io.patenaude.karmamanager.private-dictionary"
 	dictionarySelectionsKey = "io.patenaude.karmamanager.dictionary-selections"
 )
 
-func NewDictionary(name string) *Dictionary {
-	d := &Dictionary{Name: name, Words: make([]string, 50), Enabled: true}
-	return d
-}
-
-func ParseDictionary(name string, jsondata []byte) (*Dictionary, error) {
-	d := &Dictionary{Name: name}
-
-	err := json.Unmarshal(jsondata, &(d.Words))
-	if err == nil {
-		for index, word := range d.Words {
-			d.Words[index] = MarkSpaces(word)
-		}
-		return d, err
-	}
-	return nil, err
-}
-
-func ReadConfigs() ([]MainDictionaryConfig, []AddedDictionaryConfig, error) {
-	mainData, err := jsonFS.ReadFile("json/" + main_dicts_file)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var mainDicts []MainDictionaryConfig
-	err = json.Unmarshal(mainData, &mainDicts)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	addedData, err := jsonFS.ReadFile("json/" + added_dicts_file)
-	if err != nil {
-		return mainDicts, nil, err
-	}
-
-	var addedDicts []AddedDictionaryConfig
-	err = json.Unmarshal(addedData, &addedDicts)
-	if err != nil {
-		return mainDicts, nil, err
-	}
-	return mainDicts, addedDicts, nil
-}
-
-func ReadDictionaries() ([]*Dictionary, []*Dictionary, error) {
-	mainDictConfigs, addedDictConfigs, err := ReadConfigs()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var mainDicts []*Dictionary = make([]*Dictionary, len(mainDictConfigs))
-	for i, mdc := range mainDictConfigs {
-		data, err := jsonFS.ReadFile("json/" + mdc.File)
-		if err != nil {
-			return nil, nil, err
-		}
-		mainDicts[i], err = ParseDictionary(mdc.Description, data)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-
-	var addedDicts []*Dictionary = make([]*Dictionary, len(addedDictConfigs))
-	for i, adc := range addedDictConfigs {
-		data, err := jsonFS.ReadFile("json/" + adc.File)
-		if err != nil {
-			return mainDicts, nil, err
-		}
-
-		addedDicts[i], err = ParseDictionary(adc.Description, data)
-		if err != nil {
-			return mainDicts, nil, err
-		}
-		addedDicts[i].Enabled = adc.Enabled
-	}
-
-	return mainDicts, addedDicts, nil
-}
-
-func MarkSpaces(input string) string {
-	if nonSeperable {
-		return strings.ReplaceAll(input, " ", "_")
-	} else {
-		return input
-	}
-}
-
-func UnmarkSpaces(input string) string {
-	if nonSeperable {
-		return strings.ReplaceAll(input, "_", " ")
-	} else {
-		return input
-	}
-}
-
-func MergeDictionaries(excluded []string, dicts ...*Dictionary) *Dictionary {
-	var length int = 0
-	names := make([]string, 0, len(dicts))
-	words := make([]string, 0, len(dicts[0].Words))
-
-	knownWords := make(map[string]bool)
-	for _, word := range excluded {
-		knownWords[strings.ToLower(word)] = true
-	} // if they're already "known" they won't be added again
-
-	for _, d := range dicts {
-		names = append(names, d.Name)
-		for _, word := range d.Words {
-			if !knownWords[strings.ToLower(word)] {
-				knownWords[strings.ToLower(word)] = true
-				length += 1
-				words = append(words, word)
-			}
-		}
-	}
-
-	result := NewDictionary(strings.Join(names, " + "))
-	result.Words = words
-
-	return result
-}
-
-func GetPrivateDictionary(prefs fyne.Preferences) *Dictionary {
-	private := NewDictionary("Private")
+func GetPrivateDictionary(prefs fyne.Preferences) *anagram.Dictionary {
+	private := anagram.NewDictionary("Private")
 	private.Words = prefs.StringList(privateDictionaryKey)
 
 	return private
 }
 
-func SavePrivateDictionary(d *Dictionary, prefs fyne.Preferences) {
+func SavePrivateDictionary(d *anagram.Dictionary, prefs fyne.Preferences) {
 	prefs.SetStringList(privateDictionaryKey, d.Words)
 }
 
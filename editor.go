@@ -13,6 +13,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 var fontSize float32 = 32.0
@@ -37,7 +39,7 @@ type WordWidget struct {
 func NewWordWidget(index int, word string, drop func(int, fyne.Position), tap func(int)) *WordWidget {
 	ww := &WordWidget{Index: index, Word: word, dropfunc: drop}
 
-	ww.Text = canvas.NewText(UnmarkSpaces(ww.Word), theme.TextColor())
+	ww.Text = canvas.NewText(anagram.UnmarkSpaces(ww.Word), theme.TextColor())
 	ww.Text.TextStyle = fyne.TextStyle{Monospace: true}
 	ww.Text.TextSize = fontSize
 
@@ -113,9 +115,10 @@ type EditField struct {
 	surface    *fyne.Container
 	widgets    []*WordWidget
 	window     fyne.Window
+	alphabet   *anagram.Alphabet // edited words must keep their letters in it
 }
 
-func NewEditField(words []string, window fyne.Window) *EditField {
+func NewEditField(words []string, alphabet *anagram.Alphabet, window fyne.Window) *EditField {
 	ef := &EditField{}
 
 	ef.Words = make([]string, 0, len(words))
@@ -126,6 +129,7 @@ func NewEditField(words []string, window fyne.Window) *EditField {
 		ef.Words = append(ef.Words, word)
 	}
 	ef.window = window
+	ef.alphabet = alphabet
 
 	ef.surface = container.NewWithoutLayout()
 
@@ -157,21 +161,21 @@ func (ef *EditField) Initialize() {
 
 func (ef *EditField) ShowWordEdit(index int) {
 	entry := widget.NewEntry()
-	entry.Text = ef.Words[index]
+	entry.Text = anagram.UnmarkSpaces(ef.Words[index])
 	entry.Validator = func(word string) error {
-		if !NewRuneCluster(word).Equals(NewRuneCluster(ef.Words[index])) {
+		if !ef.alphabet.Cluster(word).Equals(ef.alphabet.Cluster(ef.Words[index])) {
 			return errors.New("Not equivalent")
 		}
 		return nil
 	}
 	items := []*widget.FormItem{widget.NewFormItem("", entry)}
-	d := dialog.NewForm(fmt.Sprintf("Edit %s", ef.Words[index]), "Save", "Cancel", items, func(submitted bool) {
+	d := dialog.NewForm(fmt.Sprintf("Edit %s", anagram.UnmarkSpaces(ef.Words[index])), "Save", "Cancel", items, func(submitted bool) {
 		if submitted {
-			word := entry.Text
+			word := anagram.MarkSpaces(entry.Text)
 			if word != ef.Words[index] {
 				ef.Words[index] = word
 				ef.widgets[index].Word = word
-				ef.widgets[index].Text.Text = UnmarkSpaces(word)
+				ef.widgets[index].Text.Text = anagram.UnmarkSpaces(word)
 				ef.widgets[index].ResetSize()
 				LayoutAndAnimateWordWidgets(ef.widgets, padding, ef.wordheight+padding, ef.surface.Size())
 				// ef.Initialize()
@@ -14,6 +14,8 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 const favoritesKey = "io.patenaude.karmamanager.favorites"
@@ -78,7 +80,7 @@ func localDedupFavorites(favs FavoritesSlice) FavoritesSlice {
 	seen := make(map[contentKey]bool, len(favs))
 	out := make(FavoritesSlice, 0, len(favs))
 	for _, fav := range favs {
-		k := contentKey{Normalize(fav.Input), Normalize(fav.Anagram)}
+		k := contentKey{anagram.Normalize(fav.Input), anagram.Normalize(fav.Anagram)}
 		if seen[k] {
 			continue
 		}
@@ -96,9 +98,9 @@ func SaveFavorites(favorites FavoritesSlice, prefs fyne.Preferences) {
 	prefs.SetStringList(favoritesKey, strs)
 }
 
-func ShowEditor(title, text string, submit func(string), window fyne.Window) {
+func ShowEditor(title, text string, alphabet *anagram.Alphabet, submit func(string), window fyne.Window) {
 	words := strings.Split(text, " ")
-	ef := NewEditField(words, window)
+	ef := NewEditField(words, alphabet, window)
 	d := dialog.NewCustomConfirm(title, "Save", "Cancel", ef, func(submitted bool) {
 		if submitted {
 			submit(strings.Join(ef.Words, " "))
@@ -109,9 +111,9 @@ func ShowEditor(title, text string, submit func(string), window fyne.Window) {
 	d.Show()
 }
 
-func ShowFavoriteAnagramEditor(favs *FavoritesSlice, index int, prefs fyne.Preferences, refresh func(), window fyne.Window) {
+func ShowFavoriteAnagramEditor(favs *FavoritesSlice, index int, alphabet *anagram.Alphabet, prefs fyne.Preferences, refresh func(), window fyne.Window) {
 	fav := (*favs)[index]
-	ShowEditor("Edit anagram", fav.Anagram, func(newAnagram string) {
+	ShowEditor("Edit anagram", fav.Anagram, alphabet, func(newAnagram string) {
 		if fav.Anagram != newAnagram {
 			fav.Anagram = newAnagram
 			(*favs)[index] = fav
@@ -126,10 +128,10 @@ func ShowFavoriteAnagramEditor(favs *FavoritesSlice, index int, prefs fyne.Prefe
 	}, window)
 }
 
-func ShowFavoriteInputEditor(favs *FavoritesSlice, index int, prefs fyne.Preferences, refresh func(), window fyne.Window) {
+func ShowFavoriteInputEditor(favs *FavoritesSlice, index int, alphabet *anagram.Alphabet, prefs fyne.Preferences, refresh func(), window fyne.Window) {
 	fav := (*favs)[index]
 	oldInput := fav.Input
-	ShowEditor("Edit input phrase", fav.Input, func(newInput string) {
+	ShowEditor("Edit input phrase", fav.Input, alphabet, func(newInput string) {
 		if newInput != oldInput {
 			for f_index, f := range *favs {
 				if f.Input == oldInput {
@@ -153,7 +155,7 @@ func ShowDeleteFavConfirm(favs *FavoritesSlice, id int, prefs fyne.Preferences,
 		return
 	}
 	fav := (*favs)[id]
-	dialog.ShowConfirm("Really delete?", fmt.Sprintf("Really delete \"%s\"?", fav.Anagram), func(confirmed bool) {
+	dialog.ShowConfirm("Really delete?", fmt.Sprintf("Really delete \"%s\"?", anagram.UnmarkSpaces(fav.Anagram)), func(confirmed bool) {
 		if confirmed {
 			clientID := fav.ID
 			*favs = slices.Delete(*favs, id, id+1)
@@ -226,6 +228,7 @@ type FavoritesDisplay struct {
 	list         *widget.List
 	surface      *fyne.Container
 	sendToMain   func(string)
+	alphabet     func() *anagram.Alphabet // the selected main dictionary's
 }
 
 func (fd *FavoritesDisplay) buildFlatRows() {
@@ -321,7 +324,7 @@ func (fd *FavoritesDisplay) updateListItem(id widget.ListItemID, obj fyne.Canvas
 			group := fd.groupedList[input]
 			if len(group) > 0 {
 				globalID := findGlobalFavID(fd.baseList, group[0])
-				ShowFavoriteInputEditor(fd.baseList, globalID, AppPreferences, RebuildFavorites, MainWindow)
+				ShowFavoriteInputEditor(fd.baseList, globalID, fd.alphabet(), AppPreferences, RebuildFavorites, MainWindow)
 			}
 		}
 
@@ -338,7 +341,7 @@ func (fd *FavoritesDisplay) updateListItem(id widget.ListItemID, obj fyne.Canvas
 			}
 			ShowMultiPicker("Animate which anagrams", "animate", "cancel", "shuffle", anagrams, func(chosen []string) {
 				if len(chosen) > 0 {
-					ShowAnimation("Animated anagrams...", input, chosen, MainWindow)
+					ShowAnimation("Animated anagrams...", input, chosen, fd.alphabet(), MainWindow)
 				}
 			}, MainWindow)
 		}
@@ -348,26 +351,26 @@ func (fd *FavoritesDisplay) updateListItem(id widget.ListItemID, obj fyne.Canvas
 
 		anagramLabel := anagramCont.Objects[0].(*TapLabel)
 		fav := row.fav
-		anagramLabel.Label.Text = UnmarkSpaces(fav.Anagram)
+		anagramLabel.Label.Text = anagram.UnmarkSpaces(fav.Anagram)
 		anagramLabel.Label.Refresh()
 		anagramLabel.OnTapped = func(pe *fyne.PointEvent) {
 			copyAnagramMI := fyne.NewMenuItem("Copy anagram to clipboard", func() {
-				MainWindow.Clipboard().SetContent(UnmarkSpaces(fav.Anagram))
+				MainWindow.Clipboard().SetContent(anagram.UnmarkSpaces(fav.Anagram))
 				ShowPopUpMessage("Copied to clipboard", time.Second, MainWindow)
 			})
 			copyBothMI := fyne.NewMenuItem("Copy input and anagram to clipboard", func() {
-				MainWindow.Clipboard().SetContent(fmt.Sprintf("%s ↔️ %s", fav.Input, UnmarkSpaces(fav.Anagram)))
+				MainWindow.Clipboard().SetContent(fmt.Sprintf("%s ↔️ %s", fav.Input, anagram.UnmarkSpaces(fav.Anagram)))
 				ShowPopUpMessage("Copied to clipboard", time.Second, MainWindow)
 			})
 			animateMI := fyne.NewMenuItem("Animate", func() {
-				ShowAnimation("Animated anagram...", fav.Input, []string{fav.Anagram}, MainWindow)
+				ShowAnimation("Animated anagram...", fav.Input, []string{fav.Anagram}, fd.alphabet(), MainWindow)
 			})
 			sendToMainMI := fyne.NewMenuItem("Send anagram to Find tab", func() {
 				fd.sendToMain(fav.Anagram)
 			})
 			editMI := fyne.NewMenuItem("Edit", func() {
 				globalID := findGlobalFavID(fd.baseList, fav)
-				ShowFavoriteAnagramEditor(fd.baseList, globalID, AppPreferences, RebuildFavorites, MainWindow)
+				ShowFavoriteAnagramEditor(fd.baseList, globalID, fd.alphabet(), AppPreferences, RebuildFavorites, MainWindow)
 			})
 			deleteMI := fyne.NewMenuItem("Delete", func() {
 				globalID := findGlobalFavID(fd.baseList, fav)
@@ -420,17 +423,17 @@ func ShowImportFromLinkDialog(favs *FavoritesSlice, prefs fyne.Preferences, refr
 					return
 				}
 				// Duplicate check.
-				normIn := Normalize(fav.Input)
-				normAn := Normalize(fav.Anagram)
+				normIn := anagram.Normalize(fav.Input)
+				normAn := anagram.Normalize(fav.Anagram)
 				for _, existing := range *favs {
-					if Normalize(existing.Input) == normIn && Normalize(existing.Anagram) == normAn {
+					if anagram.Normalize(existing.Input) == normIn && anagram.Normalize(existing.Anagram) == normAn {
 						dialog.ShowInformation("Already in favorites",
-							fmt.Sprintf("%q is already in your favorites.", UnmarkSpaces(fav.Anagram)), window)
+							fmt.Sprintf("%q is already in your favorites.", anagram.UnmarkSpaces(fav.Anagram)), window)
 						return
 					}
 				}
 				// Confirm and save.
-				msg := fmt.Sprintf("Import \"%s\" → \"%s\"?", fav.Input, UnmarkSpaces(fav.Anagram))
+				msg := fmt.Sprintf("Import \"%s\" → \"%s\"?", fav.Input, anagram.UnmarkSpaces(fav.Anagram))
 				dialog.ShowConfirm("Import favorite", msg, func(confirmed bool) {
 					if !confirmed {
 						return
@@ -448,10 +451,11 @@ func ShowImportFromLinkDialog(favs *FavoritesSlice, prefs fyne.Preferences, refr
 	}, window)
 }
 
-func NewFavoritesDisplay(list *FavoritesSlice, sendToMain func(string)) *FavoritesDisplay {
+func NewFavoritesDisplay(list *FavoritesSlice, sendToMain func(string), alphabet func() *anagram.Alphabet) *FavoritesDisplay {
 	fd := &FavoritesDisplay{
 		baseList:   list,
 		sendToMain: sendToMain,
+		alphabet:   alphabet,
 		openGroups: make(map[string]bool),
 	}
 	fd.RegenGroups()
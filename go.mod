@@ -4,9 +4,12 @@ go 1.25.2
 
 require (
 	fyne.io/fyne/v2 v2.7.2
+	github.com/pneumaticdeath/KarmaManager/anagram v0.0.0-00010101000000-000000000000
 	github.com/pneumaticdeath/KarmaManager/reorderlist v0.0.0-00010101000000-000000000000
 )
 
+replace github.com/pneumaticdeath/KarmaManager/anagram => ./anagram
+
 replace github.com/pneumaticdeath/KarmaManager/reorderlist => ./reorderlist
 
 require (
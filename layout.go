@@ -6,6 +6,8 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 type RuneLayoutElement struct {
@@ -13,13 +15,27 @@ type RuneLayoutElement struct {
 	Row, Col int
 }
 
-func MakeRuneLayout(input string, maxColumns int) ([]RuneLayoutElement, int) {
+// MakeRuneLayout places the letters of input on a grid maxColumns wide,
+// wrapping between words where it can and hyphenating words that are too long
+// for a line. Marked phrases (see MarkSpaces) are kept on one line, unless
+// they're too long for any line, in which case they wrap between their words.
+// Letters are folded by alphabet first, so each glyph in the layout is a
+// letter that anagrams are counted in.
+func MakeRuneLayout(input string, alphabet *anagram.Alphabet, maxColumns int) ([]RuneLayoutElement, int) {
 	layout := make([]RuneLayoutElement, 0, len(input))
-	words := strings.Split(input, " ")
+	words := make([]string, 0)
+	for _, w := range strings.Split(alphabet.FoldString(input), " ") {
+		if len([]rune(w)) >= maxColumns && strings.Contains(w, "_") {
+			words = append(words, strings.Split(w, "_")...)
+		} else {
+			words = append(words, w)
+		}
+	}
 	row := 0
 	col := 0
-	for _, word := range words {
-		if word == "" {
+	for _, w := range words {
+		word := []rune(w)
+		if len(word) == 0 {
 			continue
 		}
 
@@ -32,7 +48,7 @@ func MakeRuneLayout(input string, maxColumns int) ([]RuneLayoutElement, int) {
 				word = word[remainingColumns-1:]
 				i := 0
 				for i < len(partial) {
-					r := rune(partial[i])
+					r := partial[i]
 					layout = append(layout, RuneLayoutElement{r, row, col + i})
 					i += 1
 				}
@@ -50,11 +66,10 @@ func MakeRuneLayout(input string, maxColumns int) ([]RuneLayoutElement, int) {
 
 		i := 0
 		for i < len(word) {
-			r := rune(word[i])
-			if r == '_' {
-				r = ' '
+			r := word[i]
+			if r != '_' { // the space inside a phrase has no glyph
+				layout = append(layout, RuneLayoutElement{r, row, col + i})
 			}
-			layout = append(layout, RuneLayoutElement{r, row, col + i})
 			i += 1
 		}
 		col += len(word) + 1 // the one is for the space after the word
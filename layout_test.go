@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+func TestMakeRuneLayout(t *testing.T) {
+	layout, rows := MakeRuneLayout("foo bar", anagram.LatinAlphabet, 10)
+
+	if rows != 1 {
+		t.Errorf("Expected 1 row, got %d", rows)
+	}
+
+	if len(layout) != 6 {
+		t.Fatalf("Expected 6 glyphs, got %d", len(layout))
+	}
+
+	if layout[3].Rune != 'b' || layout[3].Col != 4 {
+		t.Errorf("Second word placed wrong: %c at %d", layout[3].Rune, layout[3].Col)
+	}
+
+	_, rows = MakeRuneLayout("foo bar", anagram.LatinAlphabet, 5)
+	if rows != 2 {
+		t.Errorf("Expected words to wrap onto 2 rows, got %d", rows)
+	}
+}
+
+func TestMakeRuneLayoutUnicode(t *testing.T) {
+	layout, _ := MakeRuneLayout("straße café", anagram.LatinAlphabet, 20)
+
+	word := ""
+	for _, e := range layout {
+		word += string(e.Rune)
+	}
+	if word != "strassecafe" {
+		t.Errorf("Expected folded glyphs, got %q", word)
+	}
+
+	last := layout[len(layout)-1]
+	if last.Col != 11 {
+		t.Errorf("Expected columns counted in letters, last glyph at %d", last.Col)
+	}
+}
+
+func TestMakeRuneLayoutPhrase(t *testing.T) {
+	// "ab new_york" fits "ab" and "new york" on separate 9 column rows, but
+	// splitting at words would have put "new" after "ab".
+	layout, rows := MakeRuneLayout("ab new_york", anagram.LatinAlphabet, 9)
+	if rows != 2 {
+		t.Fatalf("Expected the phrase on its own row, got %d rows", rows)
+	}
+	if len(layout) != 9 {
+		t.Errorf("Expected no glyph for the space in a phrase, got %d glyphs", len(layout))
+	}
+	for _, e := range layout[2:] {
+		if e.Row != 1 {
+			t.Errorf("Phrase glyph %c wrapped to row %d", e.Rune, e.Row)
+		}
+	}
+	if y := layout[len(layout)-1]; y.Col != 7 {
+		t.Errorf("Expected the space kept inside the phrase, last glyph at %d", y.Col)
+	}
+
+	// Too long for a line, so it wraps between its words instead of
+	// hyphenating.
+	layout, rows = MakeRuneLayout("new_york", anagram.LatinAlphabet, 5)
+	if rows != 2 {
+		t.Errorf("Expected long phrase on 2 rows, got %d", rows)
+	}
+	for _, e := range layout {
+		if e.Rune == '-' {
+			t.Error("Long phrase was hyphenated")
+		}
+	}
+}
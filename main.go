@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"math/rand/v2"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -17,9 +19,11 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 	"github.com/pneumaticdeath/KarmaManager/reorderlist"
 )
 
@@ -97,7 +101,7 @@ func (f *flowLayout) Layout(objects []fyne.CanvasObject, containerSize fyne.Size
 	}
 }
 
-func ShowPrivateDictSettings(private *Dictionary, saveCallback func(), window fyne.Window) {
+func ShowPrivateDictSettings(private *anagram.Dictionary, saveCallback func(), window fyne.Window) {
 	wl := NewWordList(private.Words)
 	d := dialog.NewCustom("Private words", "submit", wl, window)
 	d.Resize(fyne.NewSize(300, 500))
@@ -122,8 +126,76 @@ func ShowPrivateDictSettings(private *Dictionary, saveCallback func(), window fy
 	d.Show()
 }
 
-func ShowAnimation(title, startPhrase string, anagrams []string, window fyne.Window) {
-	ad := NewAnimationDisplay(Icon)
+// newCountSelect offers the numbers 1 to 15, with none standing for 0.
+func newCountSelect(none string, value int) *widget.Select {
+	choices := []string{none}
+	for i := 1; i <= 15; i++ {
+		choices = append(choices, fmt.Sprintf("%d", i))
+	}
+	sel := widget.NewSelect(choices, nil)
+	if value > 0 && value < len(choices) {
+		sel.SetSelectedIndex(value)
+	} else {
+		sel.SetSelectedIndex(0)
+	}
+	return sel
+}
+
+// ShowConstraintsDialog lets the user limit the number of words in each
+// anagram and the length of those words.
+func ShowConstraintsDialog(current anagram.Constraints, callback func(anagram.Constraints), window fyne.Window) {
+	minWords := newCountSelect("Any", current.MinWords)
+	maxWords := newCountSelect("Any", current.MaxWords)
+	minLength := newCountSelect("Any", current.MinWordLength)
+	maxLength := newCountSelect("Any", current.MaxWordLength)
+	commonOnly := widget.NewCheck("", nil)
+	commonOnly.SetChecked(current.MinFrequency > 0)
+	leftover := widget.NewEntry()
+	leftover.SetPlaceHolder("Letters to leave out")
+	leftover.SetText(current.Leftover)
+	maxLeftover := newCountSelect("None", current.MaxLeftover)
+	pattern := widget.NewEntry()
+	pattern.SetPlaceHolder("e.g. K???? *ing ...")
+	pattern.SetText(current.Pattern)
+	pattern.Validator = func(text string) error {
+		_, err := anagram.ParsePattern(text, nil)
+		return err
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Fewest words", minWords),
+		widget.NewFormItem("Most words", maxWords),
+		widget.NewFormItem("Shortest word", minLength),
+		widget.NewFormItem("Longest word", maxLength),
+		widget.NewFormItem("Common words only", commonOnly),
+		widget.NewFormItem("Leave out", leftover),
+		widget.NewFormItem("Spare letters", maxLeftover),
+		widget.NewFormItem("Pattern", pattern),
+	}
+	d := dialog.NewForm("Limit results", "Apply", "Cancel", items, func(submitted bool) {
+		if submitted {
+			minFrequency := 0.0
+			if commonOnly.Checked {
+				minFrequency = anagram.CommonFrequency
+			}
+			callback(anagram.Constraints{
+				MinWords:      minWords.SelectedIndex(),
+				MaxWords:      maxWords.SelectedIndex(),
+				MinWordLength: minLength.SelectedIndex(),
+				MaxWordLength: maxLength.SelectedIndex(),
+				MinFrequency:  minFrequency,
+				Leftover:      strings.TrimSpace(leftover.Text),
+				MaxLeftover:   maxLeftover.SelectedIndex(),
+				Pattern:       strings.TrimSpace(pattern.Text),
+			})
+		}
+	}, window)
+	d.Resize(fyne.NewSize(300, 500))
+	d.Show()
+}
+
+func ShowAnimation(title, startPhrase string, anagrams []string, alphabet *anagram.Alphabet, window fyne.Window) {
+	ad := NewAnimationDisplay(Icon, alphabet)
 	cd := dialog.NewCustom(title, "dismiss", ad, MainWindow)
 	cd.Resize(fyne.NewSize(400, 600))
 	cd.Show()
@@ -154,7 +226,7 @@ func ShowAnimation(title, startPhrase string, anagrams []string, window fyne.Win
 		progressDialog := dialog.NewCustomWithoutButtons("Rendering…", progressBar, MainWindow)
 		progressDialog.Show()
 		go func() {
-			adCapture := NewAnimationDisplay(Icon)
+			adCapture := NewAnimationDisplay(Icon, alphabet)
 			adCapture.surface.Resize(captureSize)
 			adCapture.Resize(captureSize)
 			gct := NewGIFCaptureTool()
@@ -242,7 +314,7 @@ func ShowMultiPicker(title, submitlabel, dismisslabel, shufflelabel string, choi
 
 	rl := reorderlist.New(choices,
 		func(item string) fyne.CanvasObject {
-			check := widget.NewCheck(UnmarkSpaces(item), func(v bool) { checkState[item] = v })
+			check := widget.NewCheck(anagram.UnmarkSpaces(item), func(v bool) { checkState[item] = v })
 			check.Checked = checkState[item]
 			return check
 		},
@@ -285,34 +357,38 @@ const interestingSearchLimit = 100000
 // restarted with that word excluded from the dictionary. All other first words
 // seen in that run are also excluded on restart to prevent double-counting.
 // Stops after interestingSearchLimit total results across all runs.
-func analyzeInterestingWords(rs *ResultSet, progress func(int, int)) (map[string]int, map[string]bool, int) {
+func analyzeInterestingWords(rs *anagram.ResultSet, progress func(int, int)) (map[string]int, map[string]bool, int) {
 	wordCount := make(map[string]int)
 	capped := make(map[string]bool)
 	totalResults := 0
-	normalizedInput := Normalize(rs.state.input)
+	alphabet := rs.Alphabet()
+	normalizedInput := alphabet.Normalize(rs.Input())
 
 	// Build set of included words so we can skip them when finding
 	// the "leading word" for capping. Included phrases are always the
 	// prefix of every result, so capping on them would be wrong.
 	includeWords := make(map[string]bool)
-	for _, phrase := range rs.state.included {
+	for _, phrase := range rs.Inclusions() {
 		for _, w := range strings.Fields(phrase) {
 			includeWords[strings.TrimSpace(w)] = true
 		}
 	}
 
-	localExcludes := make([]string, len(rs.state.excluded))
-	copy(localExcludes, rs.state.excluded)
+	localExcludes := make([]string, len(rs.Exclusions()))
+	copy(localExcludes, rs.Exclusions())
 
 	for {
-		dict := MergeDictionaries(localExcludes, rs.state.combinedDict)
-		ch := FindAnagrams(rs.state.input, rs.state.included, dict)
+		dict := anagram.MergeDictionaries(localExcludes, rs.CombinedDict())
+		ctx, cancel := context.WithCancel(context.Background())
+		opts := anagram.DefaultSearchOptions
+		opts.Constraints = rs.Constraints()
+		ch := anagram.FindAnagramsWithOptions(ctx, rs.Input(), rs.Inclusions(), dict, opts)
 
 		leadingWordCount := make(map[string]int)
 		hitCap := false
 
 		for result := range ch {
-			if Normalize(UnmarkSpaces(result)) == normalizedInput {
+			if alphabet.Normalize(anagram.UnmarkSpaces(result)) == normalizedInput {
 				continue
 			}
 
@@ -358,6 +434,7 @@ func analyzeInterestingWords(rs *ResultSet, progress func(int, int)) (map[string
 				break
 			}
 		}
+		cancel() // stop the generator if we abandoned the channel early
 
 		if !hitCap || totalResults >= interestingSearchLimit {
 			break
@@ -371,13 +448,13 @@ func analyzeInterestingWords(rs *ResultSet, progress func(int, int)) (map[string
 	return wordCount, capped, totalResults
 }
 
-func ShowInterestingWordsList(rs *ResultSet, n int, progress func(int, int), include func(string), exclude func(string), window fyne.Window) {
+func ShowInterestingWordsList(rs *anagram.ResultSet, n int, progress func(int, int), include func(string), exclude func(string), window fyne.Window) {
 	wordCount, capped, totalResults := analyzeInterestingWords(rs, progress)
 
 	// Build sorted counts
-	words := make(Counts, 0, len(wordCount))
+	words := make(anagram.Counts, 0, len(wordCount))
 	for w, c := range wordCount {
-		words = append(words, WordCount{w, c, len(w) * len(w) * c})
+		words = append(words, anagram.NewWordCount(w, c))
 	}
 	sort.Sort(words)
 	if len(words) > n {
@@ -398,7 +475,7 @@ func ShowInterestingWordsList(rs *ResultSet, n int, progress func(int, int), inc
 		if capped[words[id].Word] {
 			countStr = fmt.Sprintf("%d+", interestingWordCap)
 		}
-		label.Label.Text = fmt.Sprintf("%s %s", UnmarkSpaces(words[id].Word), countStr)
+		label.Label.Text = fmt.Sprintf("%s %s", anagram.UnmarkSpaces(words[id].Word), countStr)
 		label.OnTapped = func(pe *fyne.PointEvent) {
 			includeMI := fyne.NewMenuItem("Include", func() {
 				include(words[id].Word)
@@ -422,6 +499,24 @@ func ShowInterestingWordsList(rs *ResultSet, n int, progress func(int, int), inc
 	fyne.Do(d.Show)
 }
 
+// formatSearchProgress describes a search's progress for the progress bar,
+// with the estimates once there are any.
+func formatSearchProgress(p anagram.SearchProgress) string {
+	text := fmt.Sprintf("%.0f%%", 100*p.Fraction)
+	if p.Fraction >= 1 {
+		return text
+	}
+	if p.EstimatedTotal >= 0 {
+		text += fmt.Sprintf(", ~%d results", p.EstimatedTotal)
+	}
+	if eta := p.ETA.Round(time.Second); eta > 0 {
+		text += ", " + eta.String() + " left"
+	} else if p.ETA >= 0 {
+		text += ", almost done"
+	}
+	return text
+}
+
 func ShowPopUpMessage(message string, duration time.Duration, window fyne.Window) {
 	pulabel := widget.NewLabel(message)
 	pu := widget.NewPopUp(pulabel, window.Canvas())
@@ -708,7 +803,7 @@ func main() {
 		}()
 	}
 
-	mainDicts, addedDicts, err := ReadDictionaries()
+	mainDicts, addedDicts, err := anagram.ReadDictionaries()
 	if err != nil {
 		panic(err)
 	}
@@ -734,7 +829,13 @@ func main() {
 		mainDictNames[i] = d.Name
 	}
 
-	resultSet := NewResultSet(mainDicts, addedDicts, privateDict, 0)
+	resultSet := anagram.NewResultSet(mainDicts, addedDicts, privateDict, 0)
+	if cache, err := anagram.NewDiskCache(filepath.Join(App.Storage().RootURI().Path(), "search-cache")); err != nil {
+		log.Println("Search results won't be kept between runs:", err)
+	} else {
+		resultSet.SetDiskCache(cache)
+	}
+	App.Lifecycle().SetOnStopped(resultSet.Save)
 
 	reset_search := func() {
 	}
@@ -813,6 +914,45 @@ func main() {
 	})
 	mainSelect.SetSelectedIndex(selectedMainIndex)
 
+	modeNames := make([]string, len(anagram.Modes))
+	for i, mode := range anagram.Modes {
+		modeNames[i] = mode.String()
+	}
+	// Cryptic fodder is best first, and can be exported for setters.
+	var bestFirstCheck *widget.Check
+	exportButton := widget.NewButtonWithIcon("Export fodder", theme.DocumentSaveIcon(), func() {
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil || uc == nil {
+				return
+			}
+			defer uc.Close()
+			if err := resultSet.WriteFodder(uc); err != nil {
+				dialog.ShowError(err, MainWindow)
+			}
+		}, MainWindow)
+		fd.SetFileName("fodder.csv")
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+		fd.Show()
+	})
+	if resultSet.Mode() != anagram.CrypticMode {
+		exportButton.Hide()
+	}
+	modeSelect := widget.NewSelect(modeNames, func(name string) {
+		for _, mode := range anagram.Modes {
+			if mode.String() == name {
+				resultSet.SetMode(mode)
+				if mode == anagram.CrypticMode {
+					bestFirstCheck.SetChecked(true)
+					exportButton.Show()
+				} else {
+					exportButton.Hide()
+				}
+				return
+			}
+		}
+	})
+	modeSelect.Selected = resultSet.Mode().String()
+
 	inputdata := binding.NewString()
 	inputEntry := widget.NewEntryWithData(inputdata)
 	inputEntry.SetPlaceHolder("What are we anagramming?")
@@ -828,16 +968,47 @@ func main() {
 		reset_search()
 	})
 
+	var constraintsButton *widget.Button
+	constraintsButton = widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		ShowConstraintsDialog(resultSet.Constraints(), func(c anagram.Constraints) {
+			if c.IsZero() {
+				constraintsButton.Importance = widget.MediumImportance
+			} else {
+				constraintsButton.Importance = widget.HighImportance
+			}
+			constraintsButton.Refresh()
+			resultSet.SetConstraints(c)
+		}, MainWindow)
+	})
+
+	batchButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		ShowBatchDialog(resultSet, MainWindow)
+	})
+
 	progressBar := widget.NewProgressBar()
 	progressBar.Min = 0.0
 	progressBar.Max = 1.0
+	progressText := ""
+	progressBar.TextFormatter = func() string {
+		return progressText
+	}
 	pbCallback := func(current, goal int) {
 		fyne.Do(func() {
 			progressBar.SetValue(float64(current) / float64(goal))
+			progressText = fmt.Sprintf("%.0f%%", 100*progressBar.Value)
 			progressBar.Refresh()
 		})
 	}
-	resultSet.SetProgressCallback(pbCallback)
+	// The search reports how far through it is rather than how many
+	// results it has found.
+	resultSet.SetProgressCallback(func(int, int) {
+		p := resultSet.Progress()
+		fyne.Do(func() {
+			progressBar.SetValue(p.Fraction)
+			progressText = formatSearchProgress(p)
+			progressBar.Refresh()
+		})
+	})
 
 	interestingButton := widget.NewButton("Interesting words", nil)
 
@@ -859,12 +1030,33 @@ func main() {
 	resultSet.SetWorkingStartCallback(wbStartCallback)
 	resultSet.SetWorkingStopCallback(wbStopCallback)
 
+	// Best first and shuffled are different orders, so only one can be on.
+	var shuffleCheck *widget.Check
+	bestFirstCheck = widget.NewCheck("Best first", func(checked bool) {
+		if checked {
+			resultSet.SetOrder(anagram.RankedOrder)
+			shuffleCheck.SetChecked(false)
+		} else if resultSet.Order() == anagram.RankedOrder {
+			resultSet.SetOrder(anagram.DictionaryOrder)
+		}
+	})
+	shuffleCheck = widget.NewCheck("Shuffle", func(checked bool) {
+		if checked {
+			// A new shuffle each time it's turned on.
+			resultSet.SetShuffleSeed(uint64(time.Now().UnixNano()))
+			resultSet.SetOrder(anagram.ShuffledOrder)
+			bestFirstCheck.SetChecked(false)
+		} else if resultSet.Order() == anagram.ShuffledOrder {
+			resultSet.SetOrder(anagram.DictionaryOrder)
+		}
+	})
+
 	interestBar := container.New(layout.NewGridLayout(2), interestingButton, progressBar)
-	rightSideBar := container.NewBorder(nil, nil, nil, workingBar, interestBar)
+	rightSideBar := container.NewBorder(nil, nil, container.NewHBox(bestFirstCheck, shuffleCheck), workingBar, interestBar)
 	workingBar.Stop()
 	workingBar.Hide()
 
-	inputField := container.NewBorder(nil, nil, nil, inputClearButton, inputEntry)
+	inputField := container.NewBorder(nil, nil, modeSelect, container.NewHBox(constraintsButton, batchButton, inputClearButton), inputEntry)
 	inputBar := container.New(layout.NewAdaptiveGridLayout(2), inputField, rightSideBar)
 
 	dictionaryBar := container.New(layout.NewAdaptiveGridLayout(2), mainSelect, addedDictsContainer)
@@ -881,9 +1073,58 @@ func main() {
 	}, func(index int, object fyne.CanvasObject) { // Update entry
 		return // to be replaced later
 	})
+	exactLabel := widget.NewLabel("")
+	exactLabel.Wrapping = fyne.TextWrapWord
+	exactLabel.Hide()
+	updateExact := func() {
+		exact := resultSet.ExactAnagrams()
+		if len(exact) == 0 {
+			exactLabel.Hide()
+			return
+		}
+		words := make([]string, len(exact))
+		for i, word := range exact {
+			words[i] = anagram.UnmarkSpaces(word)
+		}
+		exactLabel.SetText("Exact anagrams: " + strings.Join(words, ", "))
+		exactLabel.Show()
+	}
+	totalLabel := widget.NewLabel("")
+	totalLabel.Hide()
+	cancelCount := func() {}
+	// updateTotal counts the current search's results in the background,
+	// giving up on the last count if it hasn't finished.
+	updateTotal := func() {
+		cancelCount()
+		if strings.TrimSpace(resultSet.Input()) == "" || resultSet.Mode() != anagram.AnagramMode {
+			totalLabel.Hide()
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelCount = cancel
+		totalLabel.SetText("Counting anagrams…")
+		totalLabel.Show()
+		go func() {
+			total, err := resultSet.TotalCount(ctx)
+			fyne.Do(func() {
+				if ctx.Err() != nil {
+					return // another search has taken over
+				}
+				if err != nil {
+					totalLabel.SetText(err.Error())
+				} else if total == 1 {
+					totalLabel.SetText("1 total anagram")
+				} else {
+					totalLabel.SetText(fmt.Sprintf("%d total anagrams", total))
+				}
+			})
+		}()
+	}
 	inputEntry.OnSubmitted = func(input string) {
 		reset_search()
 		resultSet.FindAnagrams(input)
+		updateExact()
+		updateTotal()
 	}
 
 	inclusionwords := NewWordList([]string{})
@@ -945,7 +1186,8 @@ func main() {
 	inclusionlabel := container.New(layout.NewHBoxLayout(), widget.NewLabel("Include"), inclusionaddbutton, inclusionClearButton)
 	inclusioncontainer := container.NewBorder(inclusionlabel, nil, nil, nil, inclusionwords)
 	controlscontainer := container.New(layout.NewGridLayout(2), inclusioncontainer, exclusioncontainer)
-	mainDisplay := container.New(layout.NewAdaptiveGridLayout(2), resultsDisplay, controlscontainer)
+	resultsPanel := container.NewBorder(container.NewVBox(exactLabel, totalLabel, exportButton), nil, nil, nil, resultsDisplay)
+	mainDisplay := container.New(layout.NewAdaptiveGridLayout(2), resultsPanel, controlscontainer)
 
 	resultsDisplay.UpdateItem = func(id widget.ListItemID, obj fyne.CanvasObject) {
 		label, ok := obj.(*TapLabel)
@@ -954,28 +1196,32 @@ func main() {
 		}
 		text, text_ok := resultSet.GetAt(id)
 		if text_ok {
-			label.Label.Text = fmt.Sprintf("%10d %s", id+1, UnmarkSpaces(text))
+			leftover := resultSet.LeftoverAt(id)
+			label.Label.Text = fmt.Sprintf("%10d %s", id+1, anagram.UnmarkSpaces(text))
+			if leftover != "" {
+				label.Label.Text += fmt.Sprintf("  (+%s)", leftover)
+			}
 			label.Label.TextStyle = fyne.TextStyle{Italic: false}
 			label.OnTapped = func(pe *fyne.PointEvent) {
 				input, _ := inputdata.Get()
 				copyAnagramToCBMI := fyne.NewMenuItem("Copy anagram to clipboard", func() {
-					MainWindow.Clipboard().SetContent(UnmarkSpaces(text))
+					MainWindow.Clipboard().SetContent(anagram.UnmarkSpaces(text))
 					ShowPopUpMessage("Copied to clipboard", time.Second, MainWindow)
 				})
 				copyBothToCBMI := fyne.NewMenuItem("Copy input and anagram to clipboard", func() {
-					MainWindow.Clipboard().SetContent(fmt.Sprintf("%s ↔️ %s", input, UnmarkSpaces(text)))
+					MainWindow.Clipboard().SetContent(fmt.Sprintf("%s ↔️ %s", input, anagram.UnmarkSpaces(text)))
 					ShowPopUpMessage("Copied to clipboard", time.Second, MainWindow)
 				})
 				addToFavsMI := fyne.NewMenuItem("Add to favorites", func() {
 					// first check to see if this is a duplicate
-					newInputNormalized := Normalize(input)
-					newAnagramNormalized := Normalize(text)
+					newInputNormalized := anagram.Normalize(input)
+					newAnagramNormalized := anagram.Normalize(text)
 					for _, existing := range favorites {
-						if newInputNormalized == Normalize(existing.Input) && newAnagramNormalized == Normalize(existing.Anagram) {
+						if newInputNormalized == anagram.Normalize(existing.Input) && newAnagramNormalized == anagram.Normalize(existing.Anagram) {
 							// log.Printf("Detected duplicate with \"%s\"\n", existing.Anagram)
-							dialog.ShowConfirm("Duplicate detected", fmt.Sprintf("Looks similar to \"%s\".  Add anyway?", existing.Anagram), func(addAnyway bool) {
+							dialog.ShowConfirm("Duplicate detected", fmt.Sprintf("Looks similar to \"%s\".  Add anyway?", anagram.UnmarkSpaces(existing.Anagram)), func(addAnyway bool) {
 								if addAnyway {
-									ShowEditor("Drag to reorder, click to edit", text, func(editted string) {
+									ShowEditor("Drag to reorder, click to edit", text, resultSet.Alphabet(), func(editted string) {
 										newFav := FavoriteAnagram{resultSet.CombinedDictName(), strings.TrimSpace(input), editted, newUUID()}
 										favorites = append(favorites, newFav)
 										RebuildFavorites()
@@ -992,7 +1238,7 @@ func main() {
 							return
 						}
 					}
-					ShowEditor("Drag to reorder, click to edit", text, func(editted string) {
+					ShowEditor("Drag to reorder, click to edit", text, resultSet.Alphabet(), func(editted string) {
 						// log.Println("No duplicate detected")
 						newFav := FavoriteAnagram{resultSet.CombinedDictName(), strings.TrimSpace(input), editted, newUUID()}
 						favorites = append(favorites, newFav)
@@ -1006,16 +1252,17 @@ func main() {
 				})
 				animateMI := fyne.NewMenuItem("Animate", func() {
 					input, _ = inputdata.Get()
-					ShowAnimation("Animate anagram...", input, []string{text}, MainWindow)
+					ShowAnimation("Animate anagram...", input, []string{text}, resultSet.Alphabet(), MainWindow)
 				})
+				animateMI.Disabled = leftover != "" // partial anagrams can't animate
 				words := strings.Split(text, " ")
 				includeMIs := make([]*fyne.MenuItem, len(words))
 				excludeMIs := make([]*fyne.MenuItem, len(words))
 				for index, word := range words {
-					includeMIs[index] = fyne.NewMenuItem(UnmarkSpaces(word), func() {
+					includeMIs[index] = fyne.NewMenuItem(anagram.UnmarkSpaces(word), func() {
 						includeFunc(word)
 					})
-					excludeMIs[index] = fyne.NewMenuItem(UnmarkSpaces(word), func() {
+					excludeMIs[index] = fyne.NewMenuItem(anagram.UnmarkSpaces(word), func() {
 						excludeFunc(word)
 					})
 				}
@@ -1048,7 +1295,7 @@ func main() {
 		resultsDisplay.Refresh()
 	}
 
-	favsList := NewFavoritesDisplay(&favorites, sendToMainTabFunc)
+	favsList := NewFavoritesDisplay(&favorites, sendToMainTabFunc, resultSet.Alphabet)
 
 	favsContent := favsList
 
@@ -1058,6 +1305,11 @@ func main() {
 		favsList.Refresh()
 	}
 
+	pairDicts := make([]*anagram.Dictionary, 0, len(mainDicts)+len(addedDicts))
+	pairDicts = append(pairDicts, mainDicts...)
+	pairDicts = append(pairDicts, addedDicts...)
+	pairsContent := NewPairsTab(pairDicts, privateDict, sendToMainTabFunc, MainWindow)
+
 	iconImage := canvas.NewImageFromResource(Icon)
 	iconImage.SetMinSize(fyne.NewSize(128, 128))
 	iconImage.FillMode = canvas.ImageFillContain
@@ -1078,6 +1330,7 @@ func main() {
 	content := container.NewAppTabs(
 		container.NewTabItem("Find", findContent),
 		container.NewTabItem("Favorites", favsContent),
+		container.NewTabItem("Pairs", pairsContent),
 		container.NewTabItem("About", aboutContent))
 
 	MainWindow.SetContent(content)
@@ -1087,9 +1340,14 @@ func main() {
 	}
 
 	resultSet.SetRefreshCallback(func() {
+		fyne.Do(updateExact)
+		fyne.Do(updateTotal)
 		fyne.Do(resultsDisplay.Refresh)
 		fyne.Do(resultsDisplay.ScrollToTop)
 	})
+	resultSet.SetUpdateCallback(func() {
+		fyne.Do(resultsDisplay.Refresh)
+	})
 
 	reset_search = func() {
 		// not using SetInclusions() or SetExclusions because they will refresh other fields
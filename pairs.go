@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+)
+
+// NewPairsTab lists the pairs of entries in the chosen dictionaries, or the
+// private one, that are anagrams of each other, which can be exported as CSV
+// or JSON. Tapping a pair offers to copy it or send it to the Find tab.
+func NewPairsTab(dicts []*anagram.Dictionary, private *anagram.Dictionary, sendToMain func(string), window fyne.Window) fyne.CanvasObject {
+	dicts = append(slices.Clone(dicts), private)
+	chosen := make([]bool, len(dicts))
+	dictChecks := make([]fyne.CanvasObject, len(dicts))
+	for i, d := range dicts {
+		dictChecks[i] = widget.NewCheck(d.Name, func(checked bool) {
+			chosen[i] = checked
+		})
+	}
+	minLength := newCountSelect("Any", 0)
+	crossOnly := widget.NewCheck("Different dictionaries only", nil)
+
+	var pairs []anagram.AnagramPair
+	statusLabel := widget.NewLabel("Pick dictionaries to find the anagrams in them")
+	workingBar := widget.NewActivity()
+	workingBar.Hide()
+
+	pairsList := widget.NewList(func() int {
+		return len(pairs)
+	}, func() fyne.CanvasObject {
+		return NewTapLabel("Pair")
+	}, func(id widget.ListItemID, obj fyne.CanvasObject) {
+		label, ok := obj.(*TapLabel)
+		if !ok || id >= len(pairs) {
+			return
+		}
+		pair := pairs[id]
+		first, second := anagram.UnmarkSpaces(pair.First), anagram.UnmarkSpaces(pair.Second)
+		label.Label.Text = fmt.Sprintf("%10d %s ↔️ %s", id+1, first, second)
+		if pair.FirstDictionary != pair.SecondDictionary {
+			label.Label.Text += fmt.Sprintf("  (%s, %s)", pair.FirstDictionary, pair.SecondDictionary)
+		}
+		label.OnTapped = func(pe *fyne.PointEvent) {
+			copyMI := fyne.NewMenuItem("Copy pair to clipboard", func() {
+				window.Clipboard().SetContent(fmt.Sprintf("%s ↔️ %s", first, second))
+				ShowPopUpMessage("Copied to clipboard", time.Second, window)
+			})
+			sendFirstMI := fyne.NewMenuItem("Send \""+first+"\" to Find tab", func() {
+				sendToMain(first)
+			})
+			sendSecondMI := fyne.NewMenuItem("Send \""+second+"\" to Find tab", func() {
+				sendToMain(second)
+			})
+			pumenu := fyne.NewMenu("Pop up", copyMI, sendFirstMI, sendSecondMI)
+			widget.ShowPopUpMenuAtRelativePosition(pumenu, window.Canvas(), pe.Position, label)
+		}
+		label.Refresh()
+	})
+
+	exportButton := widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() {
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil || uc == nil {
+				return
+			}
+			defer uc.Close()
+			write := anagram.WritePairsCSV
+			if strings.ToLower(uc.URI().Extension()) == ".json" {
+				write = anagram.WritePairsJSON
+			}
+			if err := write(uc, pairs); err != nil {
+				dialog.ShowError(err, window)
+			}
+		}, window)
+		fd.SetFileName("pairs.csv")
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".json"}))
+		fd.Show()
+	})
+	exportButton.Disable()
+
+	// Only the latest search is shown, so a new one cancels the last.
+	cancelFind := func() {}
+	var findButton *widget.Button
+	findButton = widget.NewButtonWithIcon("Find pairs", theme.SearchIcon(), func() {
+		selected := make([]*anagram.Dictionary, 0, len(dicts))
+		for i, d := range dicts {
+			if !chosen[i] {
+				continue
+			}
+			if d == private {
+				// The search runs in the background, so it gets a copy of
+				// the words as they are now rather than racing later edits.
+				d = &anagram.Dictionary{Name: d.Name, Words: slices.Clone(d.Words), Info: d.Info, Alphabet: d.Alphabet}
+			}
+			selected = append(selected, d)
+		}
+		if len(selected) == 0 {
+			dialog.ShowInformation("No dictionaries", "Pick at least one dictionary to look for pairs in", window)
+			return
+		}
+		opts := anagram.PairOptions{MinLetters: minLength.SelectedIndex(), CrossDictionary: crossOnly.Checked}
+
+		cancelFind()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelFind = cancel
+		statusLabel.SetText("Looking for pairs…")
+		exportButton.Disable()
+		workingBar.Show()
+		workingBar.Start()
+		go func() {
+			found, err := anagram.FindAnagramPairs(ctx, opts, selected...)
+			fyne.Do(func() {
+				if ctx.Err() != nil {
+					return // another search has taken over
+				}
+				workingBar.Stop()
+				workingBar.Hide()
+				if err != nil {
+					statusLabel.SetText(err.Error())
+					return
+				}
+				pairs = found
+				if len(pairs) == 1 {
+					statusLabel.SetText("1 pair")
+				} else {
+					statusLabel.SetText(fmt.Sprintf("%d pairs", len(pairs)))
+				}
+				if len(pairs) > 0 {
+					exportButton.Enable()
+				}
+				pairsList.Refresh()
+				pairsList.ScrollToTop()
+			})
+		}()
+	})
+
+	options := container.New(layout.NewHBoxLayout(), widget.NewLabel("Shortest word"), minLength, crossOnly, findButton, exportButton, workingBar)
+	controls := container.New(layout.NewVBoxLayout(), container.New(&flowLayout{}, dictChecks...), options, statusLabel)
+	return container.NewBorder(controls, nil, nil, nil, pairsList)
+}
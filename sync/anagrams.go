@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Limits on what a single anagram request can ask for, to keep one client
+// from tying up the server.
+const (
+	defaultPageSize       = 20
+	maxPageSize           = 1000
+	maxPagedResults       = 100000 // how far into the results paging can reach
+	defaultSearchTimeout  = 5 * time.Second
+	maxSearchTimeout      = 30 * time.Second
+	maxConcurrentSearches = 2
+	maxCachedDicts        = 3 // annotated dictionaries are big, and the VM is small
+)
+
+// anagramService answers /api/ext/anagrams requests with the same engine
+// and dictionaries as the app.
+type anagramService struct {
+	mainConfigs  []anagram.MainDictionaryConfig
+	addedConfigs []anagram.AddedDictionaryConfig
+	mainDicts    []*anagram.Dictionary
+	addedDicts   []*anagram.Dictionary
+
+	slots chan struct{} // one per search allowed to run at once
+
+	cacheLock sync.Mutex
+	cache     map[string]*cachedDict
+	cacheKeys []string // oldest first
+}
+
+// cachedDict is a combined dictionary that's built the first time a request
+// needs it. Requests for the same one wait on the build rather than starting
+// their own, and requests for other dictionaries aren't held up at all.
+type cachedDict struct {
+	once sync.Once
+	dict *anagram.Dictionary
+}
+
+func newAnagramService() (*anagramService, error) {
+	mainConfigs, addedConfigs, err := anagram.ReadConfigs()
+	if err != nil {
+		return nil, err
+	}
+	mainDicts, addedDicts, err := anagram.NewDictionaries(mainConfigs, addedConfigs)
+	if err != nil {
+		return nil, err
+	}
+	return &anagramService{
+		mainConfigs:  mainConfigs,
+		addedConfigs: addedConfigs,
+		mainDicts:    mainDicts,
+		addedDicts:   addedDicts,
+		slots:        make(chan struct{}, maxConcurrentSearches),
+		cache:        make(map[string]*cachedDict),
+	}, nil
+}
+
+// anagramRequest is the parsed query string of an anagram request.
+type anagramRequest struct {
+	Input    string
+	MainDict int
+	Added    []int
+	Include  []string
+	Exclude  []string
+	Page     int
+	PageSize int
+	Timeout  time.Duration
+	Format   string
+}
+
+// splitParam splits a comma separated parameter, marking the spaces in each
+// phrase so it's treated as one word.
+func splitParam(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) != "" {
+			items = append(items, anagram.MarkSpaces(item))
+		}
+	}
+	return items
+}
+
+// findDict looks a dictionary up by file name or description.
+func findDict(name string, descriptions, files []string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range files {
+		if name == strings.ToLower(files[i]) || name == strings.ToLower(strings.TrimSuffix(files[i], ".json")) || name == strings.ToLower(descriptions[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func intParam(query map[string][]string, name string, def int) (int, error) {
+	values := query[name]
+	if len(values) == 0 || values[0] == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(values[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a whole number", name)
+	}
+	return n, nil
+}
+
+func (s *anagramService) parseRequest(r *http.Request) (*anagramRequest, error) {
+	query := r.URL.Query()
+	req := &anagramRequest{
+		Input:   strings.TrimSpace(query.Get("input")),
+		Include: splitParam(query.Get("include")),
+		Exclude: splitParam(query.Get("exclude")),
+		Format:  query.Get("format"),
+		Timeout: defaultSearchTimeout,
+	}
+	if req.Input == "" {
+		return nil, fmt.Errorf("input is required")
+	}
+
+	mainDescriptions := make([]string, len(s.mainConfigs))
+	mainFiles := make([]string, len(s.mainConfigs))
+	for i, mdc := range s.mainConfigs {
+		mainDescriptions[i], mainFiles[i] = mdc.Description, mdc.File
+	}
+	if name := query.Get("maindict"); name != "" {
+		req.MainDict = findDict(name, mainDescriptions, mainFiles)
+		if req.MainDict < 0 {
+			return nil, fmt.Errorf("unknown main dictionary %q", name)
+		}
+	}
+
+	addedDescriptions := make([]string, len(s.addedConfigs))
+	addedFiles := make([]string, len(s.addedConfigs))
+	for i, adc := range s.addedConfigs {
+		addedDescriptions[i], addedFiles[i] = adc.Description, adc.File
+	}
+	if !query.Has("addeddicts") {
+		for i, adc := range s.addedConfigs {
+			if adc.Enabled {
+				req.Added = append(req.Added, i)
+			}
+		}
+	} else {
+		for _, name := range strings.Split(query.Get("addeddicts"), ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			index := findDict(name, addedDescriptions, addedFiles)
+			if index < 0 {
+				return nil, fmt.Errorf("unknown added dictionary %q", name)
+			}
+			req.Added = append(req.Added, index)
+		}
+	}
+
+	var err error
+	if req.Page, err = intParam(query, "page", 0); err != nil {
+		return nil, err
+	}
+	if req.PageSize, err = intParam(query, "pagesize", defaultPageSize); err != nil {
+		return nil, err
+	}
+	if req.PageSize == 0 || req.PageSize > maxPageSize {
+		return nil, fmt.Errorf("pagesize must be between 1 and %d", maxPageSize)
+	}
+	// Every result before the page has to be found and skipped.
+	if req.Page >= maxPagedResults/req.PageSize {
+		return nil, fmt.Errorf("page can't reach past the first %d results", maxPagedResults)
+	}
+
+	if t := query.Get("timeout"); t != "" {
+		req.Timeout, err = time.ParseDuration(t)
+		if err != nil || req.Timeout <= 0 {
+			return nil, fmt.Errorf("timeout must be a duration such as 5s")
+		}
+		req.Timeout = min(req.Timeout, maxSearchTimeout)
+	}
+
+	switch req.Format {
+	case "":
+		req.Format = "json"
+	case "json", "ndjson", "sse":
+	default:
+		return nil, fmt.Errorf("format must be json, ndjson or sse")
+	}
+
+	return req, nil
+}
+
+// dictionary returns the combined dictionary for the request, annotated and
+// ready to search.
+func (s *anagramService) dictionary(req *anagramRequest) *anagram.Dictionary {
+	added := make([]string, len(req.Added))
+	for i, index := range req.Added {
+		added[i] = s.addedConfigs[index].File
+	}
+	sort.Strings(added)
+	excluded := append([]string{}, req.Exclude...)
+	sort.Strings(excluded)
+	key := s.mainConfigs[req.MainDict].File + "|" + strings.Join(added, ",") + "|" + strings.ToLower(strings.Join(excluded, ","))
+
+	s.cacheLock.Lock()
+	entry, ok := s.cache[key]
+	if !ok {
+		if len(s.cacheKeys) >= maxCachedDicts {
+			delete(s.cache, s.cacheKeys[0])
+			s.cacheKeys = s.cacheKeys[1:]
+		}
+		entry = &cachedDict{}
+		s.cache[key] = entry
+		s.cacheKeys = append(s.cacheKeys, key)
+	}
+	s.cacheLock.Unlock()
+
+	entry.once.Do(func() {
+		dicts := []*anagram.Dictionary{s.mainDicts[req.MainDict]}
+		for _, index := range req.Added {
+			dicts = append(dicts, s.addedDicts[index])
+		}
+		dict := anagram.MergeDictionaries(req.Exclude, dicts...)
+		anagram.GetAnnotatedDict(dict) // build it now, while nobody else can see it
+		entry.dict = dict
+	})
+	return entry.dict
+}
+
+// anagramResult is one result, numbered from the start of the search.
+type anagramResult struct {
+	Index   int    `json:"index"`
+	Anagram string `json:"anagram"`
+}
+
+// anagramSummary says how a request finished. More is true when there are
+// results after this page; TimedOut when the search ran out of time first.
+type anagramSummary struct {
+	Input    string `json:"input"`
+	MainDict string `json:"maindict"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"pagesize"`
+	Count    int    `json:"count"`
+	More     bool   `json:"more"`
+	TimedOut bool   `json:"timedOut"`
+}
+
+// search runs the request, calling emit for each result on the page. Results
+// are unique up to word order, and the input itself is left out, as in the
+// app. It waits up to the request's timeout for a free slot, and then gives
+// the search itself the whole timeout.
+func (s *anagramService) search(ctx context.Context, req *anagramRequest, emit func(anagramResult) error) (anagramSummary, error) {
+	summary := anagramSummary{
+		Input:    req.Input,
+		MainDict: s.mainConfigs[req.MainDict].File,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+
+	wait, cancelWait := context.WithTimeout(ctx, req.Timeout)
+	defer cancelWait()
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-wait.Done():
+		return summary, apis.NewTooManyRequestsError("The server is busy, try again shortly", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, req.Timeout)
+	defer cancel()
+
+	dict := s.dictionary(req)
+	first := req.Page * req.PageSize
+	seen := anagram.NewDeduper(req.Input, dict.Alphabet)
+	index := 0
+
+	for result := range anagram.FindAnagramsContext(ctx, req.Input, req.Include, dict) {
+		if !seen.IsNew(result) {
+			continue
+		}
+
+		if index >= first+req.PageSize {
+			summary.More = true
+			break
+		}
+		if index >= first {
+			if err := emit(anagramResult{index, anagram.UnmarkSpaces(result)}); err != nil {
+				return summary, err
+			}
+			summary.Count += 1
+		}
+		index += 1
+	}
+	summary.TimedOut = !summary.More && ctx.Err() == context.DeadlineExceeded
+
+	return summary, nil
+}
+
+// handle serves GET /api/ext/anagrams. The default format collects the page
+// into one JSON object; ndjson and sse stream each result as it's found,
+// followed by the summary.
+func (s *anagramService) handle(e *core.RequestEvent) error {
+	req, err := s.parseRequest(e.Request)
+	if err != nil {
+		return apis.NewBadRequestError(err.Error(), nil)
+	}
+	ctx := e.Request.Context()
+
+	switch req.Format {
+	case "ndjson", "sse":
+		if req.Format == "ndjson" {
+			e.Response.Header().Set("Content-Type", "application/x-ndjson")
+		} else {
+			e.Response.Header().Set("Content-Type", "text/event-stream")
+			e.Response.Header().Set("Cache-Control", "no-cache")
+		}
+		e.Response.WriteHeader(http.StatusOK)
+
+		write := func(event string, v any) error {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if req.Format == "ndjson" {
+				_, err = fmt.Fprintf(e.Response, "%s\n", data)
+			} else {
+				_, err = fmt.Fprintf(e.Response, "event: %s\ndata: %s\n\n", event, data)
+			}
+			if err != nil {
+				return err
+			}
+			return e.Flush()
+		}
+
+		summary, err := s.search(ctx, req, func(r anagramResult) error {
+			return write("anagram", r)
+		})
+		if err != nil {
+			log.Println("anagram stream:", err)
+			return nil // the status has already been sent
+		}
+		return write("done", summary)
+
+	default:
+		results := make([]string, 0, req.PageSize)
+		summary, err := s.search(ctx, req, func(r anagramResult) error {
+			results = append(results, r.Anagram)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return e.JSON(http.StatusOK, struct {
+			anagramSummary
+			Results []string `json:"results"`
+		}{summary, results})
+	}
+}
+
+// handleDictionaries serves GET /api/ext/anagrams/dictionaries, listing the
+// dictionaries requests can name.
+func (s *anagramService) handleDictionaries(e *core.RequestEvent) error {
+	type dictInfo struct {
+		Description string `json:"description"`
+		File        string `json:"file"`
+		Language    string `json:"language,omitempty"`
+		Enabled     bool   `json:"enabled,omitempty"`
+	}
+	mains := make([]dictInfo, len(s.mainConfigs))
+	for i, mdc := range s.mainConfigs {
+		mains[i] = dictInfo{Description: mdc.Description, File: mdc.File, Language: mdc.Language}
+	}
+	added := make([]dictInfo, len(s.addedConfigs))
+	for i, adc := range s.addedConfigs {
+		added[i] = dictInfo{Description: adc.Description, File: adc.File, Enabled: adc.Enabled}
+	}
+	return e.JSON(http.StatusOK, map[string]any{"main": mains, "added": added})
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+func newTestService(t *testing.T) *anagramService {
+	t.Helper()
+	s, err := newAnagramService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// serve runs a GET /api/ext/anagrams request with the given query string.
+func serve(s *anagramService, query string) (*httptest.ResponseRecorder, error) {
+	rec := httptest.NewRecorder()
+	e := &core.RequestEvent{}
+	e.Request = httptest.NewRequest(http.MethodGet, "/api/ext/anagrams?"+query, nil)
+	e.Response = rec
+	return rec, s.handle(e)
+}
+
+func apiStatus(err error) int {
+	var apiErr *router.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
+type jsonPage struct {
+	anagramSummary
+	Results []string `json:"results"`
+}
+
+func servePage(t *testing.T, s *anagramService, query string) jsonPage {
+	t.Helper()
+	rec, err := serve(s, query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: status %d", query, rec.Code)
+	}
+	var page jsonPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("%s: %v in %s", query, err, rec.Body)
+	}
+	return page
+}
+
+func TestParseRequest(t *testing.T) {
+	s := newTestService(t)
+	parse := func(query string) (*anagramRequest, error) {
+		return s.parseRequest(httptest.NewRequest(http.MethodGet, "/api/ext/anagrams?"+query, nil))
+	}
+
+	req, err := parse("input=+Karma+Manager+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var enabled []int
+	for i, adc := range s.addedConfigs {
+		if adc.Enabled {
+			enabled = append(enabled, i)
+		}
+	}
+	if req.Input != "Karma Manager" || req.MainDict != 0 || !slices.Equal(req.Added, enabled) ||
+		req.Page != 0 || req.PageSize != defaultPageSize || req.Timeout != defaultSearchTimeout || req.Format != "json" {
+		t.Errorf("Unexpected defaults %+v", req)
+	}
+
+	req, err = parse("input=cat&maindict=UK+dictionary&addeddicts=names,acronyms.json&include=new+york,,cat&exclude=dog&page=2&pagesize=50&timeout=1m&format=sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.mainConfigs[req.MainDict].File != "en_GB-ise.json" {
+		t.Errorf("Expected the UK dictionary, got %s", s.mainConfigs[req.MainDict].File)
+	}
+	if len(req.Added) != 2 || s.addedConfigs[req.Added[0]].File != "names.json" || s.addedConfigs[req.Added[1]].File != "acronyms.json" {
+		t.Errorf("Unexpected added dictionaries %v", req.Added)
+	}
+	if !slices.Equal(req.Include, []string{anagram.MarkSpaces("new york"), "cat"}) || !slices.Equal(req.Exclude, []string{"dog"}) {
+		t.Errorf("Unexpected include %q and exclude %q", req.Include, req.Exclude)
+	}
+	if req.Page != 2 || req.PageSize != 50 || req.Timeout != maxSearchTimeout || req.Format != "sse" {
+		t.Errorf("Unexpected paging %+v", req)
+	}
+
+	req, err = parse("input=cat&maindict=en_au&addeddicts=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.mainConfigs[req.MainDict].File != "en_AU.json" || len(req.Added) != 0 {
+		t.Errorf("Expected only the Australian dictionary, got %+v", req)
+	}
+
+	if req, err := parse("input=cat&page=4999"); err != nil || req.Page*req.PageSize+req.PageSize != maxPagedResults {
+		t.Errorf("Expected the last page within reach, got %+v and %v", req, err)
+	}
+
+	for _, query := range []string{
+		"",
+		"input=+",
+		"input=cat&maindict=klingon",
+		"input=cat&addeddicts=places,klingon",
+		"input=cat&page=-1",
+		"input=cat&page=two",
+		"input=cat&pagesize=0",
+		"input=cat&pagesize=1001",
+		"input=cat&page=100&pagesize=1000",
+		"input=cat&page=5000",
+		"input=cat&page=99999999999999999999",
+		"input=cat&timeout=soon",
+		"input=cat&timeout=-1s",
+		"input=cat&format=xml",
+	} {
+		if _, err := parse(query); err == nil {
+			t.Errorf("Expected an error parsing %q", query)
+		}
+		if _, err := serve(s, query); apiStatus(err) != http.StatusBadRequest {
+			t.Errorf("Expected a bad request for %q, got %v", query, err)
+		}
+	}
+}
+
+func TestAnagramPaging(t *testing.T) {
+	s := newTestService(t)
+
+	all := servePage(t, s, "input=Karma+Manager&addeddicts=&pagesize=6")
+	if all.Count != 6 || len(all.Results) != 6 || !all.More || all.TimedOut {
+		t.Fatalf("Unexpected first page %+v", all)
+	}
+	if all.Input != "Karma Manager" || all.MainDict != "en_US.json" || all.PageSize != 6 {
+		t.Errorf("Unexpected summary %+v", all.anagramSummary)
+	}
+
+	first := servePage(t, s, "input=Karma+Manager&addeddicts=&pagesize=3")
+	second := servePage(t, s, "input=Karma+Manager&addeddicts=&pagesize=3&page=1")
+	if second.Page != 1 || !slices.Equal(append(first.Results, second.Results...), all.Results) {
+		t.Errorf("Pages %q and %q don't match %q", first.Results, second.Results, all.Results)
+	}
+
+	// The input itself isn't a result, so "act" is the only anagram of "cat"
+	// and there are no more after it.
+	cat := servePage(t, s, "input=cat&addeddicts=&pagesize=5")
+	if !slices.Equal(cat.Results, []string{"act"}) || cat.Count != 1 || cat.More {
+		t.Errorf("Unexpected anagrams of cat %+v", cat)
+	}
+	past := servePage(t, s, "input=cat&addeddicts=&page=3")
+	if len(past.Results) != 0 || past.Count != 0 || past.More {
+		t.Errorf("Expected an empty page past the end, got %+v", past)
+	}
+}
+
+func TestAnagramStreamFormats(t *testing.T) {
+	s := newTestService(t)
+	want := servePage(t, s, "input=Karma+Manager&addeddicts=&pagesize=4&page=1")
+
+	rec, err := serve(s, "input=Karma+Manager&addeddicts=&pagesize=4&page=1&format=ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != len(want.Results)+1 {
+		t.Fatalf("Expected %d results and a summary, got %q", len(want.Results), lines)
+	}
+	for i, line := range lines[:len(lines)-1] {
+		var result anagramResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Index != 4+i || result.Anagram != want.Results[i] {
+			t.Errorf("Expected %d %q, got %+v", 4+i, want.Results[i], result)
+		}
+	}
+	var summary anagramSummary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary != want.anagramSummary {
+		t.Errorf("Expected summary %+v, got %+v", want.anagramSummary, summary)
+	}
+
+	rec, err = serve(s, "input=Karma+Manager&addeddicts=&pagesize=4&page=1&format=sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	var events, data []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, event)
+		} else if d, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			data = append(data, d)
+		}
+	}
+	if !slices.Equal(events, []string{"anagram", "anagram", "anagram", "anagram", "done"}) || len(data) != len(events) {
+		t.Fatalf("Unexpected events %q with data %q", events, data)
+	}
+	if err := json.Unmarshal([]byte(data[len(data)-1]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary != want.anagramSummary {
+		t.Errorf("Expected summary %+v, got %+v", want.anagramSummary, summary)
+	}
+}
+
+func TestAnagramTimeout(t *testing.T) {
+	s := newTestService(t)
+
+	// Ninety nine thousand results can't be skipped in a millisecond.
+	page := servePage(t, s, "input=the+quick+brown+fox+jumps+over+the+lazy+dog&addeddicts=&pagesize=1000&page=98&timeout=1ms")
+	if !page.TimedOut || page.More || page.Count != 0 {
+		t.Errorf("Expected the search to time out, got %+v", page.anagramSummary)
+	}
+}
+
+func TestAnagramBusy(t *testing.T) {
+	s := newTestService(t)
+	for range maxConcurrentSearches {
+		s.slots <- struct{}{}
+	}
+
+	start := time.Now()
+	_, err := serve(s, "input=cat&timeout=50ms")
+	if apiStatus(err) != http.StatusTooManyRequests {
+		t.Errorf("Expected too many requests while every slot is taken, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Gave up after %v, before the timeout", elapsed)
+	}
+
+	<-s.slots
+	if page := servePage(t, s, "input=cat&addeddicts="); !slices.Equal(page.Results, []string{"act"}) {
+		t.Errorf("Expected a search once a slot is free, got %+v", page)
+	}
+}
+
+func TestAnagramDictionaryCache(t *testing.T) {
+	s := newTestService(t)
+	req := &anagramRequest{MainDict: 0, Added: []int{1}, Exclude: []string{"Dog"}}
+
+	dicts := make(chan *anagram.Dictionary, 4)
+	for range cap(dicts) {
+		go func() { dicts <- s.dictionary(req) }()
+	}
+	first := <-dicts
+	for range cap(dicts) - 1 {
+		if <-dicts != first {
+			t.Error("Expected concurrent requests to share one dictionary")
+		}
+	}
+	if first == nil || len(first.Words) == 0 {
+		t.Fatal("Expected a built dictionary")
+	}
+	if s.dictionary(&anagramRequest{MainDict: 0, Added: []int{1}, Exclude: []string{"dog"}}) != first {
+		t.Error("Expected exclusions to match regardless of case")
+	}
+
+	for i := range maxCachedDicts {
+		s.dictionary(&anagramRequest{MainDict: i + 1})
+	}
+	if len(s.cache) != maxCachedDicts || len(s.cacheKeys) != maxCachedDicts {
+		t.Errorf("Expected %d cached dictionaries, got %d", maxCachedDicts, len(s.cache))
+	}
+	if s.dictionary(req) == first {
+		t.Error("Expected the oldest dictionary to have been dropped")
+	}
+}
@@ -2,7 +2,12 @@ module github.com/pneumaticdeath/KarmaManager/sync
 
 go 1.23.0
 
-require github.com/pocketbase/pocketbase v0.27.0
+require (
+	github.com/google/uuid v1.6.0
+	github.com/pneumaticdeath/KarmaManager/anagram v0.0.0-00010101000000-000000000000
+	github.com/pocketbase/dbx v1.11.0
+	github.com/pocketbase/pocketbase v0.27.0
+)
 
 require (
 	github.com/asaskevich/govalidator v0.0.0-20230301143203-a9d515a09cc2 // indirect
@@ -14,12 +19,10 @@ require (
 	github.com/ganigeorgiev/fexpr v0.5.0 // indirect
 	github.com/go-ozzo/ozzo-validation/v4 v4.3.0 // indirect
 	github.com/golang-jwt/jwt/v5 v5.2.2 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/ncruces/go-strftime v0.1.9 // indirect
-	github.com/pocketbase/dbx v1.11.0 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/spf13/cobra v1.9.1 // indirect
@@ -37,3 +40,5 @@ require (
 	modernc.org/memory v1.9.1 // indirect
 	modernc.org/sqlite v1.37.0 // indirect
 )
+
+replace github.com/pneumaticdeath/KarmaManager/anagram => ../anagram
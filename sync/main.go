@@ -187,6 +187,16 @@ func main() {
 			return e.JSON(http.StatusNotFound, map[string]string{"status": "pending"})
 		})
 
+		anagrams, err := newAnagramService()
+		if err != nil {
+			log.Println("newAnagramService:", err)
+		} else {
+			// GET /api/ext/anagrams — runs an anagram search, see anagrams.go for parameters
+			se.Router.GET("/api/ext/anagrams", anagrams.handle)
+			// GET /api/ext/anagrams/dictionaries — lists the dictionaries searches can use
+			se.Router.GET("/api/ext/anagrams/dictionaries", anagrams.handleDictionaries)
+		}
+
 		return se.Next()
 	})
 
@@ -16,6 +16,8 @@ import (
 	"time"
 
 	"fyne.io/fyne/v2"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 const syncBaseURL = "https://karmamanager-sync.fly.dev"
@@ -364,8 +366,8 @@ func (sc *SyncClient) FullSync(favs *FavoritesSlice) error {
 			// and matches this record's content.
 			for j, fav := range *favs {
 				if fav.ID == r.ClientID &&
-					Normalize(fav.Input) == Normalize(r.Input) &&
-					Normalize(fav.Anagram) == Normalize(r.Anagram) {
+					anagram.Normalize(fav.Input) == anagram.Normalize(r.Input) &&
+					anagram.Normalize(fav.Anagram) == anagram.Normalize(r.Anagram) {
 					(*favs)[j].ID = newID
 					break
 				}
@@ -398,7 +400,7 @@ func (sc *SyncClient) FullSync(favs *FavoritesSlice) error {
 	canonical := make(map[contentKey]pbFavorite, len(serverRecords))
 	var dedupIDs []string // PB record IDs to hard-delete
 	for _, r := range serverRecords {
-		k := contentKey{Normalize(r.Input), Normalize(r.Anagram)}
+		k := contentKey{anagram.Normalize(r.Input), anagram.Normalize(r.Anagram)}
 		existing, exists := canonical[k]
 		if !exists {
 			canonical[k] = r
@@ -466,7 +468,7 @@ func (sc *SyncClient) FullSync(favs *FavoritesSlice) error {
 	seenLocal := make(map[contentKey]bool, len(*favs))
 	deduped := make(FavoritesSlice, 0, len(*favs))
 	for _, fav := range *favs {
-		k := contentKey{Normalize(fav.Input), Normalize(fav.Anagram)}
+		k := contentKey{anagram.Normalize(fav.Input), anagram.Normalize(fav.Anagram)}
 		if seenLocal[k] {
 			continue // local duplicate — drop silently
 		}
@@ -514,7 +516,7 @@ func (sc *SyncClient) FullSync(favs *FavoritesSlice) error {
 		}()
 	}
 	for _, fav := range *favs {
-		if _, exists := canonical[contentKey{Normalize(fav.Input), Normalize(fav.Anagram)}]; !exists {
+		if _, exists := canonical[contentKey{anagram.Normalize(fav.Input), anagram.Normalize(fav.Anagram)}]; !exists {
 			pushCh <- fav
 		}
 	}
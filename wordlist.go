@@ -11,6 +11,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/pneumaticdeath/KarmaManager/anagram"
 )
 
 type WordListWidget struct {
@@ -98,7 +100,7 @@ func NewWordList(words []string) *WordList {
 			return
 		}
 
-		wlw.SetText(UnmarkSpaces(wl.Words[id]))
+		wlw.SetText(anagram.UnmarkSpaces(wl.Words[id]))
 		wlw.OnDelete = func() {
 			wl.Words = slices.Delete(wl.Words, id, id+1)
 			if wl.OnDelete != nil {
@@ -123,7 +125,7 @@ func (wl *WordList) ShowAddWord(title, submit, dismiss string, onsubmit func(),
 	items := []*widget.FormItem{widget.NewFormItem("", wordEntry)}
 	d := dialog.NewForm(title, submit, dismiss, items, func(submitted bool) {
 		if submitted {
-			wl.Words = append(wl.Words, MarkSpaces(wordEntry.Text))
+			wl.Words = append(wl.Words, anagram.MarkSpaces(wordEntry.Text))
 			wl.list.Refresh()
 			if onsubmit != nil {
 				onsubmit()